@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gogaruda/auth/pkg/breach"
+	"log"
+	"os"
+)
+
+// Membangun atau memperbarui bloom filter dari dump SHA-1 HIBP:
+//
+//	go run ./cmd/breach -in pwned-passwords-sha1-ordered-by-hash.txt -out breach.bloom
+func main() {
+	in := flag.String("in", "", "path dump SHA-1 (HASH:COUNT per baris)")
+	out := flag.String("out", "breach.bloom", "path output bloom filter")
+	fpRate := flag.Float64("fp", 0.001, "tingkat false positive")
+	minCount := flag.Int("min-count", 1, "abaikan hash yang muncul kurang dari nilai ini")
+	flag.Parse()
+
+	if *in == "" {
+		fmt.Println("Gunakan: go run ./cmd/breach -in dump.txt -out breach.bloom")
+		os.Exit(1)
+	}
+
+	filter, err := breach.BuildFromFile(*in, *minCount, *fpRate)
+	if err != nil {
+		log.Fatalf("gagal membangun filter: %v", err)
+	}
+
+	// Tulis ke file sementara dulu supaya filter lama tetap utuh jika gagal
+	tmp := *out + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		log.Fatalf("gagal membuat file: %v", err)
+	}
+
+	if _, err := filter.WriteTo(f); err != nil {
+		f.Close()
+		log.Fatalf("gagal menulis filter: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("gagal menutup file: %v", err)
+	}
+
+	if err := os.Rename(tmp, *out); err != nil {
+		log.Fatalf("gagal mengganti filter lama: %v", err)
+	}
+
+	fmt.Printf("bloom filter berhasil dibuat: %s (%d hash)\n", *out, filter.Count())
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"github.com/gogaruda/auth/internal/bootstrap"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/joho/godotenv"
+	"log"
+)
+
+// Menghapus permanen user yang sudah di-soft delete lebih lama dari USER_DELETE_RETENTION,
+// dijalankan berkala lewat cron:
+//
+//	go run ./cmd/purge
+func main() {
+	_ = godotenv.Load()
+	cfg := config.LoadConfig()
+	db, err := bootstrap.SetupDatabase(cfg.DB)
+	if err != nil {
+		log.Fatal("koneksi database gagal:", err)
+	}
+
+	app, err := bootstrap.InitBootstrap(db, cfg)
+	if err != nil {
+		log.Fatal("bootstrap gagal:", err)
+	}
+
+	n, err := app.UserService.PurgeDeleted(context.Background())
+	if err != nil {
+		log.Fatalf("purge gagal setelah %d user: %v", n, err)
+	}
+
+	log.Printf("%d user dihapus permanen", n)
+}
@@ -21,7 +21,7 @@ func {{.FuncName}}(db *sql.DB) error {
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println("Gunakan: go run cmd/seed/create.go NamaSeeder")
+		fmt.Println("Gunakan: go run ./cmd/seed/create NamaSeeder")
 		return
 	}
 
@@ -18,9 +18,17 @@ func main() {
 		log.Fatal("koneksi ke database gagal:", err)
 	}
 
+	if err := cfg.Hash.Validate(); err != nil {
+		log.Fatal("parameter hash tidak valid:", err)
+	}
+
+	if err := cfg.Hash.LoadPeppers(); err != nil {
+		log.Fatal("pepper tidak valid:", err)
+	}
+
 	ut := utils.NewUtils(cfg)
 	if err := seeder.SeedRun(db, ut); err != nil {
-		log.Fatalf("seeding gagal: %w", err)
+		log.Fatalf("seeding gagal: %v", err)
 	}
 
 	fmt.Print("seeder berhasil...")
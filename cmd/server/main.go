@@ -19,8 +19,15 @@ func main() {
 
 	gin.SetMode(cfg.Mode.Debug)
 	r := gin.Default()
+	// tanpa proxy terpercaya X-Forwarded-For dari klien diabaikan, kontrol berbasis IP tidak bisa dielakkan
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("TRUSTED_PROXIES tidak valid:", err)
+	}
 
-	app := bootstrap.InitBootstrap(db, cfg)
+	app, err := bootstrap.InitBootstrap(db, cfg)
+	if err != nil {
+		log.Fatal("bootstrap gagal:", err)
+	}
 	internal.RouteRegister(r, app)
 
 	if err := r.Run(":" + cfg.Server.Port); err != nil {
@@ -0,0 +1,4 @@
+ALTER TABLE email_verifications
+  DROP COLUMN locked_until,
+  DROP COLUMN attempts,
+  DROP COLUMN code;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE email_verifications
+  ADD COLUMN code VARCHAR(64) NULL AFTER token,
+  ADD COLUMN attempts INT NOT NULL DEFAULT 0 AFTER code,
+  ADD COLUMN locked_until DATETIME NULL AFTER attempts;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE users
+  ADD COLUMN phone VARCHAR(20) NULL UNIQUE AFTER email;
\ No newline at end of file
@@ -0,0 +1,3 @@
+CREATE TABLE phone_history (
+  phone VARCHAR(20) NOT NULL PRIMARY KEY
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS phone_verifications;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE phone_verifications (
+  id VARCHAR(26) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(26) NOT NULL,
+  phone VARCHAR(20) NOT NULL,
+  purpose ENUM('verify', 'login') NOT NULL,
+  code VARCHAR(64) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  locked_until DATETIME NULL,
+  expires_at DATETIME NOT NULL,
+  is_used BOOLEAN DEFAULT FALSE,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
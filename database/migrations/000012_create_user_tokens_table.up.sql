@@ -0,0 +1,12 @@
+CREATE TABLE user_tokens (
+  id VARCHAR(26) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(26) NOT NULL,
+  purpose VARCHAR(50) NOT NULL,
+  token VARCHAR(64) NOT NULL UNIQUE,
+  payload TEXT,
+  expires_at DATETIME NOT NULL,
+  used_at DATETIME NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_user_tokens_user_purpose (user_id, purpose),
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE users
+  DROP COLUMN password_changed_at,
+  DROP COLUMN must_change_password;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE users
+  ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT false AFTER created_by_admin,
+  ADD COLUMN password_changed_at DATETIME NULL AFTER must_change_password;
\ No newline at end of file
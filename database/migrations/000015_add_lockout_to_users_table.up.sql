@@ -0,0 +1,4 @@
+ALTER TABLE users
+  ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0 AFTER password_changed_at,
+  ADD COLUMN last_failed_login_at DATETIME NULL AFTER failed_login_attempts,
+  ADD COLUMN locked_until DATETIME NULL AFTER last_failed_login_at;
\ No newline at end of file
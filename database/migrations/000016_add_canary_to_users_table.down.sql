@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN is_canary;
\ No newline at end of file
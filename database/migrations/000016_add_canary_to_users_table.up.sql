@@ -0,0 +1,2 @@
+ALTER TABLE users
+  ADD COLUMN is_canary BOOLEAN NOT NULL DEFAULT FALSE AFTER created_by_admin;
\ No newline at end of file
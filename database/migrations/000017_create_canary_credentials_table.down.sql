@@ -0,0 +1 @@
+DROP TABLE IF EXISTS canary_credentials;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE canary_credentials (
+  id VARCHAR(26) NOT NULL PRIMARY KEY,
+  kind VARCHAR(20) NOT NULL,
+  value VARCHAR(255) NOT NULL,
+  label VARCHAR(100) NOT NULL,
+  created_by VARCHAR(26) NULL,
+  last_triggered_at DATETIME NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_canary_credentials_kind_value (kind, value),
+  FOREIGN KEY(created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE username_history
+  DROP INDEX idx_username_history_user,
+  DROP COLUMN used_until,
+  DROP COLUMN used_from,
+  DROP COLUMN user_id,
+  MODIFY username VARCHAR(26) NOT NULL;
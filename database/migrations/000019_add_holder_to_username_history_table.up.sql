@@ -0,0 +1,6 @@
+ALTER TABLE username_history
+  MODIFY username VARCHAR(255) NOT NULL,
+  ADD COLUMN user_id VARCHAR(26) NULL AFTER username,
+  ADD COLUMN used_from DATETIME NULL AFTER user_id,
+  ADD COLUMN used_until DATETIME NULL AFTER used_from,
+  ADD INDEX idx_username_history_user (user_id, used_until);
@@ -0,0 +1 @@
+UPDATE username_history SET user_id = NULL, used_from = NULL WHERE used_until IS NULL;
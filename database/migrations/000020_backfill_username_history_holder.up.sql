@@ -0,0 +1,4 @@
+UPDATE username_history h
+  JOIN users u ON u.username = h.username
+  SET h.user_id = u.id, h.used_from = u.created_at
+  WHERE h.user_id IS NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE email_history
+  DROP INDEX idx_email_history_user,
+  DROP COLUMN created_at,
+  DROP COLUMN user_id,
+  MODIFY email VARCHAR(26) NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE email_history
+  MODIFY email VARCHAR(255) NOT NULL,
+  ADD COLUMN user_id VARCHAR(26) NULL AFTER email,
+  ADD COLUMN created_at DATETIME DEFAULT CURRENT_TIMESTAMP AFTER user_id,
+  ADD INDEX idx_email_history_user (user_id);
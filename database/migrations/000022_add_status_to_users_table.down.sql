@@ -0,0 +1,6 @@
+ALTER TABLE users
+  DROP INDEX idx_users_status,
+  DROP COLUMN deleted_at,
+  DROP COLUMN suspended_until,
+  DROP COLUMN status_reason,
+  DROP COLUMN status;
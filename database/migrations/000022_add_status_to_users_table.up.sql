@@ -0,0 +1,6 @@
+ALTER TABLE users
+  ADD COLUMN status ENUM('active', 'suspended', 'deactivated', 'deleted') NOT NULL DEFAULT 'active' AFTER locked_until,
+  ADD COLUMN status_reason VARCHAR(255) NULL AFTER status,
+  ADD COLUMN suspended_until DATETIME NULL AFTER status_reason,
+  ADD COLUMN deleted_at DATETIME NULL AFTER suspended_until,
+  ADD INDEX idx_users_status (status, deleted_at);
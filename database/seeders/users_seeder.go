@@ -39,16 +39,21 @@ func Users(db *sql.DB, ut utils.Utils) error {
 			return fmt.Errorf("query insert user_roles gagal: %w", err)
 		}
 
-		_, err = tx.ExecContext(ctx, `INSERT INTO username_history(username) VALUES(?)`, "superadmin")
+		_, err = tx.ExecContext(ctx, `INSERT INTO username_history(username, user_id, used_from) VALUES(?, ?, NOW())`, "superadmin", userID)
 		if err != nil {
 			return fmt.Errorf("query insert username history gagal: %w", err)
 		}
 
-		_, err = tx.ExecContext(ctx, `INSERT INTO email_history(email) VALUES(?)`, "superadmin@gmail.com")
+		_, err = tx.ExecContext(ctx, `INSERT INTO email_history(email, user_id) VALUES(?, ?)`, "superadmin@gmail.com", userID)
 		if err != nil {
 			return fmt.Errorf("query insert email history gagal: %w", err)
 		}
 
+		_, err = tx.ExecContext(ctx, `INSERT INTO password_history(user_id, password) VALUES(?, ?)`, userID, passHash)
+		if err != nil {
+			return fmt.Errorf("query insert password history gagal: %w", err)
+		}
+
 		_, err = tx.ExecContext(ctx, `INSERT INTO profiles(id, user_id, full_name, address, gender, image) VALUES(?, ?, ?, ?, ?, ?)`,
 			ut.GenerateULID(), userID, "Saya Super Admin Pertama", "Samarang - Garut", 1, "assets/images/default.jpg")
 		if err != nil {
@@ -6,7 +6,15 @@ import (
 	"github.com/gogaruda/auth/internal/middleware"
 	"github.com/gogaruda/auth/internal/repository"
 	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/alert"
+	"github.com/gogaruda/auth/pkg/breach"
+	"github.com/gogaruda/auth/pkg/captcha"
 	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/notify"
+	"github.com/gogaruda/auth/pkg/password"
+	"github.com/gogaruda/auth/pkg/ratelimit"
+	"github.com/gogaruda/auth/pkg/sms"
+	"github.com/gogaruda/auth/pkg/storage"
 	"github.com/gogaruda/auth/pkg/utils"
 )
 
@@ -16,28 +24,101 @@ type Service struct {
 	EmailVerificationService service.EmailVerificationService
 	GoogleAuthService        service.GoogleAuthService
 	UserService              service.UserService
+	PhoneService             service.PhoneService
+	PasswordService          service.PasswordService
+	CanaryService            service.CanaryService
+	DeviceService            service.DeviceService
+	ProfileService           service.ProfileService
+	EmailChangeService       service.EmailChangeService
+	// AssetsDir folder yang disajikan di /assets, kosong jika storage bukan local
+	AssetsDir string
+	// AvatarMaxSize batas ukuran file avatar, dipakai handler untuk membatasi body upload
+	AvatarMaxSize int64
 }
 
-func InitBootstrap(db *sql.DB, config *config.AppConfig) *Service {
+func InitBootstrap(db *sql.DB, config *config.AppConfig) (*Service, error) {
+	if err := config.Hash.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Hash.LoadPeppers(); err != nil {
+		return nil, err
+	}
+
+	breachChecker, err := breach.NewChecker(config.Breach)
+	if err != nil {
+		return nil, err
+	}
+
 	mail := mailer.NewMailer(config.Mail)
+	smsSender := sms.NewSmsSender(config.Sms)
+	alerter := alert.NewAlerter(config.Alert, mail)
+	renderer, err := notify.NewRenderer()
+	if err != nil {
+		return nil, err
+	}
 	ut := utils.NewUtils(config)
+	blobStore, err := storage.NewBlobStore(config.Blob)
+	if err != nil {
+		return nil, err
+	}
+	policy := password.NewPolicy(config.Pass, breachChecker)
 
 	userRepo := repository.NewUserRepository(db)
 	emailRepo := repository.NewEmailVerificationRepository(db)
 	authRepo := repository.NewAuthRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
+	phoneRepo := repository.NewPhoneVerificationRepository(db)
+	tokenRepo := repository.NewUserTokenRepository(db)
+	canaryRepo := repository.NewCanaryRepository(db)
+	deviceRepo := repository.NewUserDeviceRepository(db)
+	profileRepo := repository.NewProfileRepository(db)
 
-	userService := service.NewUserService(userRepo, authRepo, roleRepo, ut)
+	tokenService := service.NewUserTokenService(tokenRepo, mail, ut)
+	notificationService := service.NewNotificationService(mail, renderer, tokenService, config)
+	deviceService := service.NewDeviceService(deviceRepo, tokenService, mail, ut, config)
+	canaryService := service.NewCanaryService(canaryRepo, userRepo, authRepo, roleRepo, alerter, ut, config)
+	profileService := service.NewProfileService(profileRepo, userRepo, blobStore, ut, config.Avatar)
+	visibilityService := service.NewVisibilityService(userRepo, config.Visible)
+	userService := service.NewUserService(userRepo, authRepo, roleRepo, profileService, visibilityService, notificationService,
+		tokenService, ut, policy, config.Account)
 	emailService := service.NewEmailVerificationService(emailRepo, mail, ut, config.Mail, userService)
-	authService := service.NewAuthService(authRepo, roleRepo, config, ut, emailService)
-	googleService := service.NewGoogleAuthService(userRepo, roleRepo, authRepo, config, ut)
+	phoneService := service.NewPhoneService(phoneRepo, userRepo, authRepo, smsSender, ut, config.Sms)
+	authService := service.NewAuthService(authRepo, userRepo, roleRepo, config, ut, emailService, phoneService,
+		policy, tokenService, mail, canaryService, deviceService, notificationService)
+	passwordService := service.NewPasswordService(userRepo, authRepo, tokenService, mail, policy, ut, config, notificationService)
+	emailChangeService := service.NewEmailChangeService(userRepo, authRepo, tokenService, notificationService, mail, ut, config)
+	googleService := service.NewGoogleAuthService(userRepo, roleRepo, authService, config, ut, canaryService, notificationService)
+
+	captchaVerifier, err := captcha.NewVerifier(config.Cap)
+	if err != nil {
+		return nil, err
+	}
 
-	newMiddleware := middleware.NewMiddleware(db, config.JWT, config.Cors)
+	counterStore := ratelimit.NewMemoryStore()
+	limiter := ratelimit.NewLimiter(counterStore)
+	newMiddleware := middleware.NewMiddleware(db, config.JWT, config.Cors, config.Rate, limiter, counterStore,
+		canaryService, captchaVerifier, config.Cap, visibilityService, ut)
 	return &Service{
 		AuthService:              authService,
 		Middleware:               newMiddleware,
 		EmailVerificationService: emailService,
 		GoogleAuthService:        googleService,
 		UserService:              userService,
+		PhoneService:             phoneService,
+		PasswordService:          passwordService,
+		CanaryService:            canaryService,
+		DeviceService:            deviceService,
+		ProfileService:           profileService,
+		EmailChangeService:       emailChangeService,
+		AssetsDir:                localAssetsDir(config.Blob),
+		AvatarMaxSize:            config.Avatar.MaxSize,
+	}, nil
+}
+
+func localAssetsDir(c config.StorageConfig) string {
+	if c.Driver == "" || c.Driver == config.StorageLocal {
+		return c.LocalDir
 	}
+	return ""
 }
@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+type AccountConfig struct {
+	// UsernameCooldown jeda minimal antar penggantian username, 0 = tanpa jeda
+	UsernameCooldown time.Duration
+	// EmailRevertTTL masa berlaku link pembatalan ganti email yang dikirim ke alamat lama
+	EmailRevertTTL time.Duration
+	// DeletedRetention lama user yang dihapus masih bisa dipulihkan sebelum dihapus permanen
+	DeletedRetention time.Duration
+}
@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type AlertConfig struct {
+	// Emails adalah penerima alert prioritas tinggi, kosong = tidak kirim email
+	Emails         []string
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+}
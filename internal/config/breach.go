@@ -0,0 +1,8 @@
+package config
+
+type BreachConfig struct {
+	FilterPath string
+	ListPath   string
+	MinCount   int
+	FPRate     float64
+}
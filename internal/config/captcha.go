@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+const (
+	CaptchaNone       = "none"
+	CaptchaRecaptcha  = "recaptcha"
+	CaptchaHcaptcha   = "hcaptcha"
+	CaptchaTurnstile  = "turnstile"
+	CaptchaAlwaysPass = "always-pass"
+	CaptchaAlwaysFail = "always-fail"
+)
+
+type CaptchaConfig struct {
+	Provider string
+	Secret   string
+	// VerifyURL mengganti endpoint siteverify bawaan provider, misalnya untuk server tiruan lokal
+	VerifyURL string
+	// MinScore hanya berlaku untuk reCAPTCHA v3
+	MinScore float64
+	Timeout  time.Duration
+	// LoginAfterFailures adalah jumlah gagal login dari satu IP sebelum login wajib captcha,
+	// 0 = selalu wajib, negatif = login tidak pernah wajib captcha
+	LoginAfterFailures int
+	FailureWindow      time.Duration
+}
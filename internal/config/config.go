@@ -10,13 +10,28 @@ import (
 )
 
 type AppConfig struct {
-	DB     DBConfig
-	JWT    JWTConfig
-	Server ServerConfig
-	Mode   GinModeConfig
-	Cors   CORSConfig
-	Mail   EmailConfig
-	Google *oauth2.Config
+	DB      DBConfig
+	JWT     JWTConfig
+	Hash    HashConfig
+	Pass    PasswordConfig
+	Breach  BreachConfig
+	Lock    LockoutConfig
+	Rate    RateLimitConfig
+	Cap     CaptchaConfig
+	Sec     SecurityConfig
+	Alert   AlertConfig
+	Notify  NotificationConfig
+	Device  DeviceConfig
+	Account AccountConfig
+	Visible VisibilityConfig
+	Server  ServerConfig
+	Mode    GinModeConfig
+	Cors    CORSConfig
+	Mail    EmailConfig
+	Sms     SmsConfig
+	Blob    StorageConfig
+	Avatar  AvatarConfig
+	Google  *oauth2.Config
 }
 
 func LoadConfig() *AppConfig {
@@ -33,25 +48,148 @@ func LoadConfig() *AppConfig {
 			Secret:         os.Getenv("JWT_SECRET"),
 			AccessTokenTTL: 15 * time.Minute,
 		},
+		Hash: HashConfig{
+			Algorithm:         getEnvOrDefault("PASSWORD_HASH_ALGORITHM", HashArgon2id),
+			BcryptCost:        getEnvIntOrDefault("PASSWORD_BCRYPT_COST", 10),
+			Argon2Memory:      getArgon2Param("ARGON2_MEMORY", 64*1024, Argon2MaxMemory),
+			Argon2Iterations:  getArgon2Param("ARGON2_ITERATIONS", 3, Argon2MaxIterations),
+			Argon2Parallelism: uint8(getArgon2Param("ARGON2_PARALLELISM", 2, Argon2MaxParallelism)),
+			Argon2SaltLength:  getArgon2Param("ARGON2_SALT_LENGTH", 16, Argon2MaxSaltLength),
+			Argon2KeyLength:   getArgon2Param("ARGON2_KEY_LENGTH", 32, Argon2MaxKeyLength),
+			Peppers:           loadPeppers("PASSWORD_PEPPERS"),
+			PepperVersion:     getEnvIntOrDefault("PASSWORD_PEPPER_VERSION", 0),
+			PepperFile:        os.Getenv("PASSWORD_PEPPER_FILE"),
+		},
+		Pass: PasswordConfig{
+			MinLength:         getEnvIntOrDefault("PASSWORD_MIN_LENGTH", 6),
+			MaxLength:         getEnvIntOrDefault("PASSWORD_MAX_LENGTH", 128),
+			RequireUpper:      getEnvBoolOrDefault("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:      getEnvBoolOrDefault("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:      getEnvBoolOrDefault("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:     getEnvBoolOrDefault("PASSWORD_REQUIRE_SYMBOL", false),
+			BannedWords:       getEnvListOrDefault("PASSWORD_BANNED_WORDS", []string{"password", "qwerty", "123456"}),
+			RejectSimilar:     getEnvBoolOrDefault("PASSWORD_REJECT_SIMILAR", true),
+			ResetTTL:          getEnvDurationOrDefault("PASSWORD_RESET_TTL", 30*time.Minute),
+			HistoryCount:      getEnvIntOrDefault("PASSWORD_HISTORY_COUNT", 5),
+			AdminHistoryCount: getEnvIntOrDefault("PASSWORD_HISTORY_ADMIN_COUNT", 10),
+			ExpiryByRole:      loadPasswordExpiry("PASSWORD_EXPIRY_DAYS"),
+		},
+		Breach: BreachConfig{
+			FilterPath: os.Getenv("BREACH_FILTER_PATH"),
+			ListPath:   os.Getenv("BREACH_LIST_PATH"),
+			MinCount:   getEnvIntOrDefault("BREACH_MIN_COUNT", 1),
+			FPRate:     getEnvFloatOrDefault("BREACH_FP_RATE", 0.001),
+		},
+		Lock: LockoutConfig{
+			Threshold:  getEnvIntOrDefault("LOGIN_LOCKOUT_THRESHOLD", 5),
+			Duration:   getEnvDurationOrDefault("LOGIN_LOCKOUT_DURATION", 30*time.Minute),
+			DelayAfter: getEnvIntOrDefault("LOGIN_DELAY_AFTER", 2),
+			BaseDelay:  getEnvDurationOrDefault("LOGIN_DELAY_BASE", time.Second),
+			MaxDelay:   getEnvDurationOrDefault("LOGIN_DELAY_MAX", 30*time.Second),
+		},
+		Rate: RateLimitConfig{
+			Enabled:           getEnvBoolOrDefault("RATE_LIMIT_ENABLED", true),
+			Login:             getRateLimitRule("RATE_LIMIT_LOGIN", RateLimitRule{Limit: 10, Window: time.Minute, KeyBy: RateLimitByBoth}),
+			Register:          getRateLimitRule("RATE_LIMIT_REGISTER", RateLimitRule{Limit: 5, Window: time.Hour, KeyBy: RateLimitByIP}),
+			EmailVerification: getRateLimitRule("RATE_LIMIT_EMAIL_VERIFICATION", RateLimitRule{Limit: 10, Window: 10 * time.Minute, KeyBy: RateLimitByIP}),
+			GoogleCallback:    getRateLimitRule("RATE_LIMIT_GOOGLE_CALLBACK", RateLimitRule{Limit: 20, Window: time.Minute, KeyBy: RateLimitByIP}),
+			PasswordForgot:    getRateLimitRule("RATE_LIMIT_PASSWORD_FORGOT", RateLimitRule{Limit: 5, Window: time.Hour, KeyBy: RateLimitByBoth}),
+			PasswordReset:     getRateLimitRule("RATE_LIMIT_PASSWORD_RESET", RateLimitRule{Limit: 10, Window: 10 * time.Minute, KeyBy: RateLimitByIP}),
+			AccountLink:       getRateLimitRule("RATE_LIMIT_ACCOUNT_LINK", RateLimitRule{Limit: 10, Window: 10 * time.Minute, KeyBy: RateLimitByIP}),
+		},
+		Cap: CaptchaConfig{
+			Provider:           getEnvOrDefault("CAPTCHA_PROVIDER", CaptchaNone),
+			Secret:             os.Getenv("CAPTCHA_SECRET"),
+			VerifyURL:          os.Getenv("CAPTCHA_VERIFY_URL"),
+			MinScore:           getEnvFloatOrDefault("CAPTCHA_MIN_SCORE", 0.5),
+			Timeout:            getEnvDurationOrDefault("CAPTCHA_TIMEOUT", 5*time.Second),
+			LoginAfterFailures: getEnvIntOrDefault("CAPTCHA_LOGIN_AFTER_FAILURES", 3),
+			FailureWindow:      getEnvDurationOrDefault("CAPTCHA_FAILURE_WINDOW", time.Hour),
+		},
+		Sec: SecurityConfig{
+			Hardened:       getEnvBoolOrDefault("SECURITY_HARDENED_MODE", false),
+			CanaryTokenTTL: getEnvDurationOrDefault("CANARY_TOKEN_TTL", 365*24*time.Hour),
+		},
+		Alert: AlertConfig{
+			Emails:         getEnvListOrDefault("ALERT_EMAILS", nil),
+			WebhookURL:     os.Getenv("ALERT_WEBHOOK_URL"),
+			WebhookSecret:  os.Getenv("ALERT_WEBHOOK_SECRET"),
+			WebhookTimeout: getEnvDurationOrDefault("ALERT_WEBHOOK_TIMEOUT", 5*time.Second),
+		},
+		Notify: NotificationConfig{
+			NewLogin:        getEnvBoolOrDefault("NOTIFY_NEW_LOGIN", true),
+			PasswordChanged: getEnvBoolOrDefault("NOTIFY_PASSWORD_CHANGED", true),
+			EmailChanged:    getEnvBoolOrDefault("NOTIFY_EMAIL_CHANGED", true),
+			GoogleLinked:    getEnvBoolOrDefault("NOTIFY_GOOGLE_LINKED", true),
+			RoleChanged:     getEnvBoolOrDefault("NOTIFY_ROLE_CHANGED", true),
+			MFADisabled:     getEnvBoolOrDefault("NOTIFY_MFA_DISABLED", true),
+			RevokeTTL:       getEnvDurationOrDefault("NOTIFY_REVOKE_TTL", 7*24*time.Hour),
+		},
+		Device: DeviceConfig{
+			ConfirmNewDevice: getEnvBoolOrDefault("LOGIN_CONFIRM_NEW_DEVICE", true),
+			ElevatedRoles:    getEnvListOrDefault("LOGIN_CONFIRM_ROLES", []string{"super admin", "admin"}),
+			ConfirmTTL:       getEnvDurationOrDefault("LOGIN_CONFIRM_TTL", 15*time.Minute),
+		},
+		Account: AccountConfig{
+			UsernameCooldown: getEnvDurationOrDefault("USERNAME_CHANGE_COOLDOWN", 30*24*time.Hour),
+			EmailRevertTTL:   getEnvDurationOrDefault("EMAIL_REVERT_TTL", 7*24*time.Hour),
+			DeletedRetention: getEnvDurationOrDefault("USER_DELETE_RETENTION", 30*24*time.Hour),
+		},
+		Visible: loadVisibility("USER_HIDDEN_ROLES"),
 		Server: ServerConfig{
-			Port: getEnvOrDefault("SERVER_PORT", "8080"),
+			Port:           getEnvOrDefault("SERVER_PORT", "8080"),
+			TrustedProxies: getEnvListOrDefault("TRUSTED_PROXIES", nil),
 		},
 		Mode: GinModeConfig{
 			Debug: getModeOrDefault("GIN_MODE", "debug"),
 		},
 		Cors: CORSConfig{
 			AllowOrigins:     strings.Split(os.Getenv("CORS_ALLOW_ORIGINS"), ","),
-			AllowMethods:     strings.Split(os.Getenv("CORS_ALLOW_METHODS"), ","),
+			AllowMethods:     getEnvListOrDefault("CORS_ALLOW_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE"}),
 			AllowHeaders:     strings.Split(os.Getenv("CORS_ALLOW_HEADERS"), ","),
 			AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
 		},
 		Mail: EmailConfig{
-			MailHost:        os.Getenv("MAIL_HOST"),
-			MailPort:        port,
-			MailUsername:    os.Getenv("MAIL_USERNAME"),
-			MailPassword:    os.Getenv("MAIL_PASSWORD"),
-			MailFromAddress: os.Getenv("MAIL_FROM_ADDRESS"),
-			FrontVerifyUrl:  os.Getenv("FRONTEND_VERIFY_URL"),
+			MailHost:            os.Getenv("MAIL_HOST"),
+			MailPort:            port,
+			MailUsername:        os.Getenv("MAIL_USERNAME"),
+			MailPassword:        os.Getenv("MAIL_PASSWORD"),
+			MailFromAddress:     os.Getenv("MAIL_FROM_ADDRESS"),
+			FrontVerifyUrl:      os.Getenv("FRONTEND_VERIFY_URL"),
+			FrontResetUrl:       os.Getenv("FRONTEND_RESET_URL"),
+			FrontUnlockUrl:      os.Getenv("FRONTEND_UNLOCK_URL"),
+			FrontRevokeUrl:      os.Getenv("FRONTEND_REVOKE_URL"),
+			FrontConfirmUrl:     os.Getenv("FRONTEND_LOGIN_CONFIRM_URL"),
+			FrontEmailChangeUrl: os.Getenv("FRONTEND_EMAIL_CHANGE_URL"),
+			FrontEmailRevertUrl: os.Getenv("FRONTEND_EMAIL_REVERT_URL"),
+			VerifyMode:          getEnvOrDefault("EMAIL_VERIFY_MODE", VerifyModeLink),
+			OTPMaxAttempts:      getEnvIntOrDefault("EMAIL_OTP_MAX_ATTEMPTS", 5),
+			OTPLockout:          getEnvDurationOrDefault("EMAIL_OTP_LOCKOUT", 15*time.Minute),
+			OTPResendCooldown:   getEnvDurationOrDefault("EMAIL_OTP_RESEND_COOLDOWN", time.Minute),
+		},
+		Sms: SmsConfig{
+			Driver:             getEnvOrDefault("SMS_DRIVER", "log"),
+			LogPath:            os.Getenv("SMS_LOG_PATH"),
+			DefaultCountryCode: getEnvOrDefault("PHONE_DEFAULT_COUNTRY_CODE", "62"),
+			OTPMaxAttempts:     getEnvIntOrDefault("SMS_OTP_MAX_ATTEMPTS", 5),
+			OTPLockout:         getEnvDurationOrDefault("SMS_OTP_LOCKOUT", 15*time.Minute),
+			OTPResendCooldown:  getEnvDurationOrDefault("SMS_OTP_RESEND_COOLDOWN", time.Minute),
+		},
+		Blob: StorageConfig{
+			Driver:      getEnvOrDefault("STORAGE_DRIVER", StorageLocal),
+			LocalDir:    getEnvOrDefault("STORAGE_LOCAL_DIR", "assets"),
+			PublicURL:   os.Getenv("STORAGE_PUBLIC_URL"),
+			S3Endpoint:  os.Getenv("S3_ENDPOINT"),
+			S3Region:    getEnvOrDefault("S3_REGION", "us-east-1"),
+			S3Bucket:    os.Getenv("S3_BUCKET"),
+			S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			S3SecretKey: os.Getenv("S3_SECRET_KEY"),
+			S3PathStyle: getEnvBoolOrDefault("S3_PATH_STYLE", false),
+		},
+		Avatar: AvatarConfig{
+			MaxSize:      int64(getEnvIntOrDefault("AVATAR_MAX_SIZE", 2*1024*1024)),
+			Sizes:        loadAvatarSizes("AVATAR_SIZES", []int{64, 128, 256}),
+			DefaultImage: getEnvOrDefault("AVATAR_DEFAULT_IMAGE", "/assets/images/default.jpg"),
 		},
 		Google: &oauth2.Config{
 			ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
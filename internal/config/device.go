@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+type DeviceConfig struct {
+	// ConfirmNewDevice menahan login dari perangkat baru untuk role di ElevatedRoles
+	// sampai dikonfirmasi lewat link email. Perangkat dikenali dari prefix IP dan User-Agent,
+	// keduanya bisa ditiru penyerang yang tahu perangkat korban, lihat TRUSTED_PROXIES
+	ConfirmNewDevice bool
+	ElevatedRoles    []string
+	ConfirmTTL       time.Duration
+}
@@ -1,5 +1,13 @@
 package config
 
+import "time"
+
+const (
+	VerifyModeLink = "link"
+	VerifyModeCode = "code"
+	VerifyModeBoth = "both"
+)
+
 type EmailConfig struct {
 	MailHost        string
 	MailPort        int
@@ -7,4 +15,26 @@ type EmailConfig struct {
 	MailPassword    string
 	MailFromAddress string
 	FrontVerifyUrl  string
+	FrontResetUrl   string
+	FrontUnlockUrl  string
+	FrontRevokeUrl  string
+	FrontConfirmUrl string
+	// link konfirmasi ganti email (ke alamat baru) dan pembatalannya (ke alamat lama)
+	FrontEmailChangeUrl string
+	FrontEmailRevertUrl string
+	VerifyMode          string
+	OTPMaxAttempts      int
+	OTPLockout          time.Duration
+	// OTPResendCooldown jeda minimal sebelum email verifikasi baru bisa diminta
+	OTPResendCooldown time.Duration
+}
+
+// SendLink menandakan email verifikasi berisi link ke FrontVerifyUrl
+func (c EmailConfig) SendLink() bool {
+	return c.VerifyMode != VerifyModeCode
+}
+
+// SendCode menandakan email verifikasi berisi kode OTP 6 digit
+func (c EmailConfig) SendCode() bool {
+	return c.VerifyMode == VerifyModeCode || c.VerifyMode == VerifyModeBoth
 }
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func getEnvIntOrDefault(key string, fallback int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+func getEnvDurationOrDefault(key string, fallback time.Duration) time.Duration {
+	val, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+func getEnvBoolOrDefault(key string, fallback bool) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// getEnvListOrDefault membaca daftar yang dipisah koma, item kosong diabaikan
+func getEnvListOrDefault(key string, fallback []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvFloatOrDefault(key string, fallback float64) float64 {
+	val, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// getEnvMap membaca pasangan "key:value" yang dipisah koma, contoh "admin:90,editor:180"
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getEnvListOrDefault(key, nil) {
+		k, v, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
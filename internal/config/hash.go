@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+// batas parameter argon2id, dipakai juga saat membaca hash dari database supaya
+// hash yang rusak atau sengaja diubah tidak bisa menghabiskan memori atau membuat panic
+const (
+	Argon2MaxMemory      = 1 << 20 // KiB (1 GiB)
+	Argon2MaxIterations  = 64
+	Argon2MaxParallelism = 64
+	// key kosong atau terlalu pendek membuat perbandingan hash selalu cocok atau mudah ditebak
+	Argon2MinKeyLength  = 16
+	Argon2MaxKeyLength  = 64
+	Argon2MinSaltLength = 8
+	Argon2MaxSaltLength = 64
+)
+
+type HashConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+	// pepper per versi, tidak pernah disimpan di database
+	Peppers map[int][]byte
+	// versi pepper untuk hash baru, 0 = tanpa pepper
+	PepperVersion int
+	// file berisi baris "versi:pepper", digabung dengan Peppers saat LoadPeppers
+	PepperFile string
+}
+
+// getArgon2Param membaca parameter argon2 dari env, nilai di luar 1..max menjadi 0 supaya ditolak Validate
+func getArgon2Param(key string, fallback, max int) uint32 {
+	val := getEnvIntOrDefault(key, fallback)
+	if val < 1 || val > max {
+		return 0
+	}
+	return uint32(val)
+}
+
+// Validate memastikan algoritma dan parameternya masuk akal, dipanggil saat startup
+func (c *HashConfig) Validate() error {
+	switch c.Algorithm {
+	case HashBcrypt:
+		if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("PASSWORD_BCRYPT_COST harus antara %d dan %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return nil
+	case HashArgon2id:
+	default:
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM %q tidak dikenal, gunakan %s atau %s", c.Algorithm, HashArgon2id, HashBcrypt)
+	}
+
+	if c.Argon2Iterations == 0 {
+		return fmt.Errorf("ARGON2_ITERATIONS harus antara 1 dan %d", Argon2MaxIterations)
+	}
+	if c.Argon2Parallelism == 0 {
+		return fmt.Errorf("ARGON2_PARALLELISM harus antara 1 dan %d", Argon2MaxParallelism)
+	}
+	if c.Argon2Memory < 8*uint32(c.Argon2Parallelism) {
+		return fmt.Errorf("ARGON2_MEMORY harus antara %d (8 x ARGON2_PARALLELISM) dan %d KiB",
+			8*uint32(c.Argon2Parallelism), Argon2MaxMemory)
+	}
+	if c.Argon2SaltLength < Argon2MinSaltLength {
+		return fmt.Errorf("ARGON2_SALT_LENGTH harus antara %d dan %d", Argon2MinSaltLength, Argon2MaxSaltLength)
+	}
+	if c.Argon2KeyLength < Argon2MinKeyLength {
+		return fmt.Errorf("ARGON2_KEY_LENGTH harus antara %d dan %d", Argon2MinKeyLength, Argon2MaxKeyLength)
+	}
+	return nil
+}
+
+// loadPeppers membaca pasangan "versi:pepper" dari env, contoh "1:rahasia-lama,2:rahasia-baru"
+func loadPeppers(key string) map[int][]byte {
+	peppers := make(map[int][]byte)
+	for k, v := range getEnvMap(key) {
+		version, err := strconv.Atoi(k)
+		if err != nil || version <= 0 || v == "" {
+			continue
+		}
+		peppers[version] = []byte(v)
+	}
+	return peppers
+}
+
+// LoadPeppers membaca PepperFile lalu memastikan versi pepper aktif tersedia.
+// Jika PepperVersion kosong, versi tertinggi yang dipakai.
+func (c *HashConfig) LoadPeppers() error {
+	if c.Peppers == nil {
+		c.Peppers = make(map[int][]byte)
+	}
+
+	if c.PepperFile != "" {
+		f, err := os.Open(c.PepperFile)
+		if err != nil {
+			return fmt.Errorf("buka file pepper gagal: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+
+			k, v, ok := strings.Cut(text, ":")
+			version, err := strconv.Atoi(strings.TrimSpace(k))
+			if !ok || err != nil || version <= 0 || strings.TrimSpace(v) == "" {
+				return fmt.Errorf("file pepper baris %d tidak valid, gunakan format versi:pepper", line)
+			}
+			c.Peppers[version] = []byte(strings.TrimSpace(v))
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("baca file pepper gagal: %w", err)
+		}
+	}
+
+	if c.PepperVersion == 0 {
+		for version := range c.Peppers {
+			if version > c.PepperVersion {
+				c.PepperVersion = version
+			}
+		}
+		return nil
+	}
+
+	if _, ok := c.Peppers[c.PepperVersion]; !ok {
+		return fmt.Errorf("pepper versi %d tidak ditemukan", c.PepperVersion)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type LockoutConfig struct {
+	// Threshold adalah jumlah gagal login berturut-turut sebelum akun dikunci, 0 = nonaktif
+	Threshold int
+	Duration  time.Duration
+	// DelayAfter adalah jumlah gagal login sebelum jeda bertingkat mulai berlaku
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
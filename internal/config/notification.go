@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// NotificationConfig mengatur email pemberitahuan keamanan, tiap event bisa dimatikan sendiri
+type NotificationConfig struct {
+	NewLogin        bool
+	PasswordChanged bool
+	EmailChanged    bool
+	GoogleLinked    bool
+	RoleChanged     bool
+	MFADisabled     bool
+	// RevokeTTL adalah masa berlaku link "bukan saya" untuk mengeluarkan semua sesi
+	RevokeTTL time.Duration
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PasswordConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	BannedWords   []string
+	RejectSimilar bool
+	ResetTTL      time.Duration
+	// jumlah password terakhir yang tidak boleh dipakai ulang, 0 = nonaktif
+	HistoryCount      int
+	AdminHistoryCount int
+	// masa berlaku password per role, role tanpa entri tidak pernah kadaluarsa
+	ExpiryByRole map[string]time.Duration
+}
+
+func loadPasswordExpiry(key string) map[string]time.Duration {
+	expiry := make(map[string]time.Duration)
+	for role, val := range getEnvMap(key) {
+		days, err := strconv.Atoi(val)
+		if err != nil || days <= 0 {
+			continue
+		}
+		expiry[strings.ToLower(role)] = time.Duration(days) * 24 * time.Hour
+	}
+	return expiry
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RateLimitByIP         = "ip"
+	RateLimitByIdentifier = "identifier"
+	RateLimitByBoth       = "both"
+)
+
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+	// KeyBy menentukan kunci counter: ip, identifier atau both (keduanya dihitung terpisah)
+	KeyBy string
+}
+
+type RateLimitConfig struct {
+	Enabled           bool
+	Login             RateLimitRule
+	Register          RateLimitRule
+	EmailVerification RateLimitRule
+	GoogleCallback    RateLimitRule
+	PasswordForgot    RateLimitRule
+	PasswordReset     RateLimitRule
+	// AccountLink untuk link dari email: konfirmasi login, buka kunci dan cabut sesi
+	AccountLink RateLimitRule
+}
+
+// getRateLimitRule membaca <prefix> dengan format "jumlah/durasi", contoh "5/1m",
+// dan <prefix>_KEY untuk jenis kunci
+func getRateLimitRule(prefix string, fallback RateLimitRule) RateLimitRule {
+	rule := fallback
+	if limit, window, ok := strings.Cut(os.Getenv(prefix), "/"); ok {
+		l, err := strconv.Atoi(strings.TrimSpace(limit))
+		w, werr := time.ParseDuration(strings.TrimSpace(window))
+		if err == nil && werr == nil {
+			rule.Limit = l
+			rule.Window = w
+		}
+	}
+
+	switch key := os.Getenv(prefix + "_KEY"); key {
+	case RateLimitByIP, RateLimitByIdentifier, RateLimitByBoth:
+		rule.KeyBy = key
+	}
+
+	return rule
+}
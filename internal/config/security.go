@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type SecurityConfig struct {
+	// Hardened menyeragamkan respon login, register dan pemulihan akun
+	// agar tidak bisa dipakai untuk menebak akun yang terdaftar
+	Hardened bool
+	// CanaryTokenTTL adalah masa berlaku token umpan yang dibuat admin
+	CanaryTokenTTL time.Duration
+}
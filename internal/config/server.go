@@ -4,6 +4,9 @@ import "os"
 
 type ServerConfig struct {
 	Port string
+	// TrustedProxies IP/CIDR reverse proxy yang header X-Forwarded-For-nya dipercaya.
+	// Kosong berarti tidak ada, ClientIP selalu alamat koneksi langsung.
+	TrustedProxies []string
 }
 
 func getEnvOrDefault(key, fallback string) string {
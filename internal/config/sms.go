@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type SmsConfig struct {
+	Driver             string
+	LogPath            string
+	DefaultCountryCode string
+	OTPMaxAttempts     int
+	OTPLockout         time.Duration
+	// OTPResendCooldown jeda minimal sebelum kode baru untuk tujuan yang sama bisa diminta
+	OTPResendCooldown time.Duration
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"sort"
+	"strconv"
+)
+
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+type StorageConfig struct {
+	Driver string
+	// LocalDir folder penyimpanan driver local, disajikan di /assets
+	LocalDir string
+	// PublicURL prefix URL file, kosong = dibentuk dari endpoint S3 atau /assets
+	PublicURL   string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PathStyle memakai endpoint/bucket/key, dibutuhkan MinIO dan stand-in lokal lain
+	S3PathStyle bool
+}
+
+type AvatarConfig struct {
+	MaxSize int64
+	// ukuran sisi (px) yang dibuat, gambar terbesar disimpan di profiles.image
+	Sizes        []int
+	DefaultImage string
+}
+
+// loadAvatarSizes membaca daftar ukuran seperti "64,128,256", diurutkan dari kecil ke besar
+func loadAvatarSizes(key string, fallback []int) []int {
+	var sizes []int
+	for _, item := range getEnvListOrDefault(key, nil) {
+		size, err := strconv.Atoi(item)
+		if err != nil || size <= 0 || size > 2048 {
+			continue
+		}
+		sizes = append(sizes, size)
+	}
+
+	if len(sizes) == 0 {
+		return fallback
+	}
+	sort.Ints(sizes)
+	return sizes
+}
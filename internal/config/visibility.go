@@ -0,0 +1,33 @@
+package config
+
+import "strings"
+
+// VisibilityConfig menentukan role target yang disembunyikan dari setiap role pelihat
+// pada GET /users dan endpoint /users/:id. Role pelihat yang tidak tercantum tidak bisa
+// melihat user mana pun.
+type VisibilityConfig struct {
+	HiddenRoles map[string][]string
+}
+
+// loadVisibility membaca format "pelihat:role1|role2", contoh: super admin:,admin:super admin|admin
+func loadVisibility(key string) VisibilityConfig {
+	pairs := getEnvMap(key)
+	if len(pairs) == 0 {
+		return VisibilityConfig{HiddenRoles: map[string][]string{
+			"super admin": {},
+			"admin":       {"super admin", "admin"},
+		}}
+	}
+
+	hidden := make(map[string][]string, len(pairs))
+	for viewer, roles := range pairs {
+		list := []string{}
+		for _, role := range strings.Split(roles, "|") {
+			if role = strings.TrimSpace(role); role != "" {
+				list = append(list, strings.ToLower(role))
+			}
+		}
+		hidden[strings.ToLower(viewer)] = list
+	}
+	return VisibilityConfig{HiddenRoles: hidden}
+}
@@ -0,0 +1,15 @@
+package request
+
+type CanaryCredentialRequest struct {
+	Kind       string `json:"kind" binding:"required,oneof=identifier token"`
+	Label      string `json:"label" binding:"required,max=100"`
+	Identifier string `json:"identifier" binding:"required_if=Kind identifier,max=255"`
+}
+
+func (r *CanaryCredentialRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"kind":       r.Kind,
+		"label":      r.Label,
+		"identifier": r.Identifier,
+	}
+}
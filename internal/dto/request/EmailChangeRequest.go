@@ -0,0 +1,13 @@
+package request
+
+type EmailChangeRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	// Password wajib untuk akun yang punya password, akun Google saja boleh kosong
+	Password string `json:"password"`
+}
+
+func (r *EmailChangeRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"email": r.Email,
+	}
+}
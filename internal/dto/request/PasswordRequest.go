@@ -0,0 +1,37 @@
+package request
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (p *ChangePasswordRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (p *ForgotPasswordRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"email": p.Email,
+	}
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (p *ResetPasswordRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
+
+type AdminResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (p *AdminResetPasswordRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
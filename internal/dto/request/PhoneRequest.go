@@ -0,0 +1,22 @@
+package request
+
+type PhoneRequest struct {
+	Phone string `json:"phone" binding:"required,max=20"`
+}
+
+func (p *PhoneRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"phone": p.Phone,
+	}
+}
+
+type PhoneLoginRequest struct {
+	Phone string `json:"phone" binding:"required,max=20"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+func (p *PhoneLoginRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"phone": p.Phone,
+	}
+}
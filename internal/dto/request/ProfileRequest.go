@@ -0,0 +1,30 @@
+package request
+
+type ProfileRequest struct {
+	FullName string  `json:"full_name" binding:"required,max=125"`
+	Address  *string `json:"address" binding:"omitempty,max=1000"`
+	Gender   *string `json:"gender" binding:"omitempty,oneof=laki-laki perempuan"`
+}
+
+func (r *ProfileRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"full_name": r.FullName,
+		"address":   r.Address,
+		"gender":    r.Gender,
+	}
+}
+
+// ProfileUpdateRequest hanya mengubah field yang dikirim
+type ProfileUpdateRequest struct {
+	FullName *string `json:"full_name" binding:"omitempty,min=1,max=125"`
+	Address  *string `json:"address" binding:"omitempty,max=1000"`
+	Gender   *string `json:"gender" binding:"omitempty,oneof=laki-laki perempuan"`
+}
+
+func (r *ProfileUpdateRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"full_name": r.FullName,
+		"address":   r.Address,
+		"gender":    r.Gender,
+	}
+}
@@ -3,8 +3,10 @@ package request
 type RegisterRequest struct {
 	Username string   `json:"username" binding:"required,excludesall= "`
 	Email    string   `json:"email" binding:"required,email"`
-	Password string   `json:"password" binding:"required,min=6"`
+	Password string   `json:"password" binding:"required"`
 	Roles    []string `json:"roles" binding:"required"`
+	// Profile opsional, dibuat dalam transaksi yang sama dengan user
+	Profile *ProfileRequest `json:"profile"`
 }
 
 func (r *RegisterRequest) Sanitize() map[string]any {
@@ -0,0 +1,11 @@
+package request
+
+// TokenRequest dipakai endpoint yang menjalankan token dari link email. Halaman frontend
+// mengirim token lewat POST, sehingga prefetch link oleh klien email tidak mengubah apa pun.
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (t *TokenRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
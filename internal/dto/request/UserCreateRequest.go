@@ -1,10 +1,14 @@
 package request
 
+import "time"
+
 type UserCreateRequest struct {
 	Username string   `json:"username" binding:"required,excludesall= "`
 	Email    string   `json:"email" binding:"required,email"`
-	Password string   `json:"password" binding:"required,min=6"`
+	Password string   `json:"password" binding:"required"`
 	Roles    []string `json:"roles" binding:"required"`
+	// Profile opsional, dibuat dalam transaksi yang sama dengan user
+	Profile *ProfileRequest `json:"profile"`
 }
 
 func (u *UserCreateRequest) Sanitize() map[string]any {
@@ -13,3 +17,34 @@ func (u *UserCreateRequest) Sanitize() map[string]any {
 		"email":    u.Email,
 	}
 }
+
+// UserUpdateRequest dipakai admin, hanya field yang dikirim yang diubah
+type UserUpdateRequest struct {
+	Username   *string  `json:"username" binding:"omitempty,min=1,excludesall= "`
+	Email      *string  `json:"email" binding:"omitempty,email,max=255"`
+	IsVerified *bool    `json:"is_verified"`
+	Roles      []string `json:"roles"`
+}
+
+func (u *UserUpdateRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"username": u.Username,
+		"email":    u.Email,
+	}
+}
+
+// UserStatusRequest memindahkan status akun, active juga dipakai untuk memulihkan user yang dihapus
+type UserStatusRequest struct {
+	Status string  `json:"status" binding:"required,oneof=active suspended deactivated"`
+	Reason *string `json:"reason" binding:"omitempty,max=255"`
+	// Until batas akhir suspend (RFC3339), kosong berarti sampai dicabut admin
+	Until *time.Time `json:"until"`
+}
+
+func (u *UserStatusRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"status": u.Status,
+		"reason": u.Reason,
+		"until":  u.Until,
+	}
+}
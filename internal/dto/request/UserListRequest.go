@@ -0,0 +1,23 @@
+package request
+
+import "time"
+
+// UserListRequest query string GET /users, semua filter opsional
+type UserListRequest struct {
+	Search         string     `form:"search" binding:"omitempty,max=100"`
+	Role           string     `form:"role"`
+	Status         string     `form:"status" binding:"omitempty,oneof=active suspended deactivated deleted"`
+	IsVerified     *bool      `form:"is_verified"`
+	CreatedByAdmin *bool      `form:"created_by_admin"`
+	Google         *bool      `form:"google"`
+	CreatedFrom    *time.Time `form:"created_from" time_format:"2006-01-02"`
+	// CreatedTo inklusif, user yang dibuat pada tanggal tersebut ikut tampil
+	CreatedTo *time.Time `form:"created_to" time_format:"2006-01-02"`
+	// Sort created_at, updated_at, username, email atau full_name, awalan "-" untuk menurun
+	Sort  string `form:"sort"`
+	Page  int    `form:"page,default=1" binding:"min=1"`
+	Limit int    `form:"limit,default=10" binding:"min=1,max=100"`
+	// Cursor mengaktifkan pagination keyset, kirim kosong (?cursor=) untuk halaman pertama
+	// lalu isi dengan meta.next/meta.prev. Tanpa cursor tetap memakai page/limit.
+	Cursor *string `form:"cursor"`
+}
@@ -0,0 +1,11 @@
+package request
+
+type UsernameChangeRequest struct {
+	Username string `json:"username" binding:"required,excludesall= ,max=255"`
+}
+
+func (r *UsernameChangeRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"username": r.Username,
+	}
+}
@@ -0,0 +1,9 @@
+package request
+
+type VerifyCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+func (v *VerifyCodeRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
@@ -0,0 +1,21 @@
+package response
+
+import "time"
+
+type CanaryAccountResponse struct {
+	ID        string    `json:"id"`
+	Username  *string   `json:"username"`
+	Email     string    `json:"email"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CanaryCredentialResponse struct {
+	ID              string     `json:"id"`
+	Kind            string     `json:"kind"`
+	Label           string     `json:"label"`
+	Identifier      string     `json:"identifier,omitempty"`
+	Token           string     `json:"token,omitempty"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
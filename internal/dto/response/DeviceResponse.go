@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type DeviceResponse struct {
+	ID          string    `json:"id"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	Current     bool      `json:"current"`
+}
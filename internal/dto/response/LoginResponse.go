@@ -1,5 +1,8 @@
 package response
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token              string `json:"token,omitempty"`
+	MustChangePassword bool   `json:"must_change_password,omitempty"`
+	// PendingConfirmation berarti login ditahan sampai dikonfirmasi lewat email
+	PendingConfirmation bool `json:"pending_confirmation,omitempty"`
 }
@@ -0,0 +1,30 @@
+package response
+
+type MeResponse struct {
+	ID                 string           `json:"id"`
+	Username           *string          `json:"username"`
+	Email              string           `json:"email"`
+	Phone              *string          `json:"phone"`
+	IsVerified         bool             `json:"is_verified"`
+	CreatedByAdmin     bool             `json:"created_by_admin"`
+	MustChangePassword bool             `json:"must_change_password"`
+	Roles              []string         `json:"roles"`
+	Profile            *ProfileResponse `json:"profile"`
+	Identities         IdentityResponse `json:"identities"`
+}
+
+// IdentityResponse menunjukkan cara login yang terhubung ke akun
+type IdentityResponse struct {
+	Password bool `json:"password"`
+	Google   bool `json:"google"`
+	Phone    bool `json:"phone"`
+}
+
+type ProfileResponse struct {
+	FullName string  `json:"full_name"`
+	Address  *string `json:"address"`
+	Gender   *string `json:"gender"`
+	Image    *string `json:"image"`
+	// Images berisi URL avatar per ukuran (px), kosong jika memakai gambar default
+	Images map[int]string `json:"images,omitempty"`
+}
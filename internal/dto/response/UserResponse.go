@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 type UserResponse struct {
 	ID             string
 	Username       *string
@@ -7,5 +9,18 @@ type UserResponse struct {
 	GoogleID       *string
 	IsVerified     bool
 	CreatedByAdmin bool
+	IsLocked       bool
+	LockedUntil    *time.Time
+	FailedLogins   int
+	Status         string
+	StatusReason   *string
+	SuspendedUntil *time.Time
 	Roles          []RoleResponse
+	Profile        *ProfileResponse
+}
+
+// Cursor posisi halaman berikut/sebelumnya pada pagination keyset, kosong jika tidak ada
+type Cursor struct {
+	Next string
+	Prev string
 }
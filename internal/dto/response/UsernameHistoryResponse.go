@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type UsernameHistoryResponse struct {
+	Username  string     `json:"username"`
+	UserID    *string    `json:"user_id"`
+	UsedFrom  *time.Time `json:"used_from"`
+	UsedUntil *time.Time `json:"used_until"`
+	// HeldAt bernilai true jika username dipegang UserID pada waktu yang ditanyakan
+	HeldAt bool `json:"held_at"`
+}
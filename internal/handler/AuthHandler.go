@@ -28,11 +28,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	if err := h.authService.Register(c.Request.Context(), req); err != nil {
-		apperror.HandleHTTPError(c, err)
+		handlePasswordError(c, h.valid, &req, "password", err)
 		return
 	}
 
-	res.Created(nil, "registrasi berhasil")
+	res.Created(nil, "registrasi berhasil, silahkan cek email")
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -42,15 +42,94 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(c.Request.Context(), req)
+	result, err := h.authService.Login(c.Request.Context(), req)
+	if err != nil {
+		// dicatat di context supaya CaptchaMiddleware menghitung gagal login dari kode errornya
+		_ = c.Error(err)
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(result, loginMessage(result), nil)
+}
+
+func (h *AuthHandler) RequestPhoneLogin(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.PhoneRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.RequestPhoneLogin(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "jika nomor terdaftar, kode OTP akan dikirim", nil)
+}
+
+func (h *AuthHandler) LoginWithPhone(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.PhoneLoginRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	result, err := h.authService.LoginWithPhone(c.Request.Context(), req)
+	if err != nil {
+		// dicatat di context supaya CaptchaMiddleware menghitung gagal login dari kode errornya
+		_ = c.Error(err)
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(result, loginMessage(result), nil)
+}
+
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.TokenRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.UnlockAccount(c.Request.Context(), req.Token); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "kunci akun berhasil dibuka, silahkan login", nil)
+}
+
+func (h *AuthHandler) RevokeSessions(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.TokenRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.RevokeSessions(c.Request.Context(), req.Token); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "semua sesi login sudah dikeluarkan, silahkan ganti password Anda", nil)
+}
+
+func (h *AuthHandler) ConfirmLogin(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.TokenRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	result, err := h.authService.ConfirmLogin(c.Request.Context(), req.Token)
 	if err != nil {
 		apperror.HandleHTTPError(c, err)
 		return
 	}
 
-	res.OK(dto.LoginResponse{
-		Token: token,
-	}, "login berhasil", nil)
+	res.OK(result, loginMessage(result), nil)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
@@ -63,3 +142,13 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	res.OK(nil, "logout success!", nil)
 }
+
+func loginMessage(result *dto.LoginResponse) string {
+	if result.PendingConfirmation {
+		return "login dari perangkat baru, silahkan konfirmasi lewat link yang dikirim ke email"
+	}
+	if result.MustChangePassword {
+		return "login berhasil, password harus diganti terlebih dahulu"
+	}
+	return "login berhasil"
+}
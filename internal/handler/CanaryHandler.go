@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+	"github.com/gogaruda/valigo"
+)
+
+type CanaryHandler struct {
+	service service.CanaryService
+	valid   *valigo.Valigo
+}
+
+func NewCanaryHandler(s service.CanaryService, v *valigo.Valigo) *CanaryHandler {
+	return &CanaryHandler{service: s, valid: v}
+}
+
+func (h *CanaryHandler) CreateAccount(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.UserCreateRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.CreateAccount(c.Request.Context(), &req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.Created(nil, "akun canary berhasil dibuat")
+}
+
+func (h *CanaryHandler) ListAccounts(c *gin.Context) {
+	res := response.NewResponder(c)
+	accounts, err := h.service.ListAccounts(c.Request.Context())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(accounts, "query ok", nil)
+}
+
+func (h *CanaryHandler) CreateCredential(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.CanaryCredentialRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	cred, err := h.service.CreateCredential(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.Created(cred, "kredensial canary berhasil dibuat, simpan token karena tidak akan ditampilkan lagi")
+}
+
+func (h *CanaryHandler) ListCredentials(c *gin.Context) {
+	res := response.NewResponder(c)
+	creds, err := h.service.ListCredentials(c.Request.Context())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(creds, "query ok", nil)
+}
+
+func (h *CanaryHandler) DeleteCredential(c *gin.Context) {
+	res := response.NewResponder(c)
+	if err := h.service.DeleteCredential(c.Request.Context(), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "kredensial canary berhasil dihapus", nil)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+)
+
+type DeviceHandler struct {
+	service service.DeviceService
+}
+
+func NewDeviceHandler(s service.DeviceService) *DeviceHandler {
+	return &DeviceHandler{service: s}
+}
+
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	res := response.NewResponder(c)
+	devices, err := h.service.List(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(devices, "query ok", nil)
+}
+
+func (h *DeviceHandler) ForgetDevice(c *gin.Context) {
+	res := response.NewResponder(c)
+	if err := h.service.Forget(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "perangkat berhasil dihapus", nil)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+	"github.com/gogaruda/valigo"
+)
+
+type EmailChangeHandler struct {
+	service service.EmailChangeService
+	valid   *valigo.Valigo
+}
+
+func NewEmailChangeHandler(s service.EmailChangeService, v *valigo.Valigo) *EmailChangeHandler {
+	return &EmailChangeHandler{service: s, valid: v}
+}
+
+func (h *EmailChangeHandler) RequestChange(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.EmailChangeRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Request(c.Request.Context(), userID.(string), &req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "link konfirmasi sudah dikirim ke email baru", nil)
+}
+
+func (h *EmailChangeHandler) ConfirmChange(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.TokenRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.Confirm(c.Request.Context(), req.Token); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "email berhasil diganti", nil)
+}
+
+func (h *EmailChangeHandler) RevertChange(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.TokenRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.Revert(c.Request.Context(), req.Token); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "penggantian email dibatalkan, semua sesi sudah dikeluarkan", nil)
+}
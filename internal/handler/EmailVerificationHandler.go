@@ -3,16 +3,19 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/request"
 	"github.com/gogaruda/auth/internal/service"
 	"github.com/gogaruda/auth/pkg/response"
+	"github.com/gogaruda/valigo"
 )
 
 type EmailVerificationHandler struct {
 	service service.EmailVerificationService
+	valid   *valigo.Valigo
 }
 
-func NewEmailVerificationHandler(s service.EmailVerificationService) *EmailVerificationHandler {
-	return &EmailVerificationHandler{service: s}
+func NewEmailVerificationHandler(s service.EmailVerificationService, v *valigo.Valigo) *EmailVerificationHandler {
+	return &EmailVerificationHandler{service: s, valid: v}
 }
 
 func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
@@ -30,3 +33,30 @@ func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
 
 	res.OK(nil, "Email berhasil di verifikasi", nil)
 }
+
+func (h *EmailVerificationHandler) VerifyCode(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.VerifyCodeRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.VerifyCode(c.Request.Context(), userID.(string), req.Code); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "Email berhasil di verifikasi, silahkan login ulang", nil)
+}
+
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	res := response.NewResponder(c)
+	userID, _ := c.Get("user_id")
+	if err := h.service.ResendVerification(c.Request.Context(), userID.(string)); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "verifikasi email sudah dikirim ulang", nil)
+}
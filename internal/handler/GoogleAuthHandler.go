@@ -3,7 +3,6 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/gogaruda/apperror"
-	dto "github.com/gogaruda/auth/internal/dto/response"
 	"github.com/gogaruda/auth/internal/service"
 	"github.com/gogaruda/auth/pkg/response"
 	"net/http"
@@ -25,13 +24,11 @@ func (h *GoogleAuthHandler) GoogleLogin(c *gin.Context) {
 func (h *GoogleAuthHandler) GoogleCallback(c *gin.Context) {
 	res := response.NewResponder(c)
 	code := c.Query("code")
-	token, err := h.service.Callback(c.Request.Context(), code)
+	result, err := h.service.Callback(c.Request.Context(), code)
 	if err != nil {
 		apperror.HandleHTTPError(c, err)
 		return
 	}
 
-	res.OK(dto.LoginResponse{
-		Token: token,
-	}, "login berhasil", nil)
+	res.OK(result, loginMessage(result), nil)
 }
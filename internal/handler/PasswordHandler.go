@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+	"github.com/gogaruda/valigo"
+)
+
+type PasswordHandler struct {
+	service service.PasswordService
+	valid   *valigo.Valigo
+}
+
+func NewPasswordHandler(s service.PasswordService, v *valigo.Valigo) *PasswordHandler {
+	return &PasswordHandler{service: s, valid: v}
+}
+
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ChangePasswordRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.ChangePassword(c.Request.Context(), userID.(string), req); err != nil {
+		handlePasswordError(c, h.valid, &req, "new_password", err)
+		return
+	}
+
+	res.OK(nil, "password berhasil diubah, silahkan login ulang", nil)
+}
+
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ForgotPasswordRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "link reset password sudah dikirim ke email", nil)
+}
+
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ResetPasswordRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req); err != nil {
+		handlePasswordError(c, h.valid, &req, "new_password", err)
+		return
+	}
+
+	res.OK(nil, "password berhasil direset, silahkan login", nil)
+}
+
+func (h *PasswordHandler) AdminResetPassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.AdminResetPasswordRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.AdminResetPassword(c.Request.Context(), actorFromContext(c), c.Param("id"), req); err != nil {
+		handlePasswordError(c, h.valid, &req, "new_password", err)
+		return
+	}
+
+	res.OK(nil, "password berhasil direset, user wajib menggantinya saat login", nil)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+	"github.com/gogaruda/valigo"
+)
+
+type PhoneHandler struct {
+	service service.PhoneService
+	valid   *valigo.Valigo
+}
+
+func NewPhoneHandler(s service.PhoneService, v *valigo.Valigo) *PhoneHandler {
+	return &PhoneHandler{service: s, valid: v}
+}
+
+func (h *PhoneHandler) RequestVerification(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.PhoneRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.RequestVerification(c.Request.Context(), userID.(string), req.Phone); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "kode OTP sudah dikirim ke nomor telepon", nil)
+}
+
+func (h *PhoneHandler) Verify(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.VerifyCodeRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Verify(c.Request.Context(), userID.(string), req.Code); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "nomor telepon berhasil diverifikasi", nil)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+	"github.com/gogaruda/valigo"
+	"io"
+	"net/http"
+)
+
+// multipartOverhead ruang untuk boundary dan header part di luar isi file avatar
+const multipartOverhead = 64 << 10
+
+type ProfileHandler struct {
+	service service.ProfileService
+	valid   *valigo.Valigo
+	maxSize int64
+}
+
+// NewProfileHandler menerima AVATAR_MAX_SIZE untuk membatasi body upload sebelum dibaca
+func NewProfileHandler(s service.ProfileService, v *valigo.Valigo, maxSize int64) *ProfileHandler {
+	return &ProfileHandler{service: s, valid: v, maxSize: maxSize}
+}
+
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	res := response.NewResponder(c)
+	profile, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(profile, "query ok", nil)
+}
+
+func (h *ProfileHandler) CreateProfile(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ProfileRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if !h.manage(c) {
+		return
+	}
+
+	profile, err := h.service.Create(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.Created(profile, "profil berhasil dibuat")
+}
+
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ProfileUpdateRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if !h.manage(c) {
+		return
+	}
+
+	profile, err := h.service.Update(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(profile, "profil berhasil diperbarui", nil)
+}
+
+func (h *ProfileHandler) UploadMyAvatar(c *gin.Context) {
+	res := response.NewResponder(c)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apperror.HandleHTTPError(c, apperror.New("[FILE_TOO_LARGE]",
+				fmt.Sprintf("ukuran file maksimal %d KB", h.maxSize/1024), err, 413))
+			return
+		}
+		res.BadRequest(nil, "file avatar wajib dikirim dengan field avatar")
+		return
+	}
+	defer file.Close()
+
+	// satu byte lebih dari batas cukup untuk membuat service menolak file yang terlalu besar
+	data, err := io.ReadAll(io.LimitReader(file, h.maxSize+1))
+	if err != nil {
+		res.BadRequest(nil, "gagal membaca file avatar")
+		return
+	}
+
+	profile, err := h.service.UploadAvatar(c.Request.Context(), c.GetString("user_id"), data, header.Header.Get("Content-Type"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(profile, "avatar berhasil diperbarui", nil)
+}
+
+func (h *ProfileHandler) DeleteMyAvatar(c *gin.Context) {
+	h.deleteAvatar(c, c.GetString("user_id"))
+}
+
+func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
+	if !h.manage(c) {
+		return
+	}
+
+	h.deleteAvatar(c, c.Param("id"))
+}
+
+func (h *ProfileHandler) deleteAvatar(c *gin.Context, userID string) {
+	res := response.NewResponder(c)
+	profile, err := h.service.DeleteAvatar(c.Request.Context(), userID)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(profile, "avatar berhasil dihapus", nil)
+}
+
+// manage menolak perubahan profil user lain yang tidak boleh dikelola actor
+func (h *ProfileHandler) manage(c *gin.Context) bool {
+	if err := h.service.CheckManage(c.Request.Context(), actorFromContext(c), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return false
+	}
+	return true
+}
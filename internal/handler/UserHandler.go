@@ -7,7 +7,7 @@ import (
 	"github.com/gogaruda/auth/internal/service"
 	"github.com/gogaruda/auth/pkg/response"
 	"github.com/gogaruda/valigo"
-	"strconv"
+	"time"
 )
 
 type UserHandler struct {
@@ -21,11 +21,24 @@ func NewUserHandler(s service.UserService, v *valigo.Valigo) *UserHandler {
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
 	res := response.NewResponder(c)
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
+	var req request.UserListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.BadRequest(nil, "query tidak valid, periksa filter, tanggal (YYYY-MM-DD) dan page/limit")
+		return
+	}
 
-	users, total, err := h.service.GetAll(c.Request.Context(), limit, offset)
+	if req.Cursor != nil {
+		users, cursor, err := h.service.GetAllByCursor(c.Request.Context(), actorFromContext(c), &req)
+		if err != nil {
+			apperror.HandleHTTPError(c, err)
+			return
+		}
+
+		res.OK(users, "query ok", &response.MetaData{Limit: req.Limit, Next: cursor.Next, Prev: cursor.Prev})
+		return
+	}
+
+	users, total, err := h.service.GetAll(c.Request.Context(), actorFromContext(c), &req)
 	if err != nil {
 		apperror.HandleHTTPError(c, err)
 		return
@@ -33,8 +46,8 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 
 	meta := response.MetaData{
 		Total: total,
-		Page:  page,
-		Limit: limit,
+		Page:  req.Page,
+		Limit: req.Limit,
 	}
 
 	res.OK(users, "query ok", &meta)
@@ -47,10 +60,143 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Create(c.Request.Context(), &req); err != nil {
-		apperror.HandleHTTPError(c, err)
+	if err := h.service.Create(c.Request.Context(), actorFromContext(c), &req); err != nil {
+		handlePasswordError(c, h.valid, &req, "password", err)
 		return
 	}
 
 	res.Created(nil, "user berhasil dibuat")
 }
+
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	res := response.NewResponder(c)
+	if err := h.service.Unlock(c.Request.Context(), actorFromContext(c), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "kunci akun berhasil dibuka", nil)
+}
+
+func (h *UserHandler) GetMe(c *gin.Context) {
+	res := response.NewResponder(c)
+	me, err := h.service.Me(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(me, "query ok", nil)
+}
+
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ProfileUpdateRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	me, err := h.service.UpdateMe(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(me, "profil berhasil diperbarui", nil)
+}
+
+func (h *UserHandler) ChangeUsername(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.UsernameChangeRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ChangeUsername(c.Request.Context(), c.GetString("user_id"), &req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "username berhasil diganti", nil)
+}
+
+// UsernameHistory menjawab "siapa pemegang username X pada waktu T", at memakai format RFC3339 dan default sekarang
+func (h *UserHandler) UsernameHistory(c *gin.Context) {
+	res := response.NewResponder(c)
+	username := c.Query("username")
+	if username == "" {
+		res.BadRequest(nil, "username tidak boleh kosong")
+		return
+	}
+
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			res.BadRequest(nil, "format at harus RFC3339, contoh 2025-01-31T08:00:00+07:00")
+			return
+		}
+		at = parsed
+	}
+
+	history, err := h.service.UsernameHolder(c.Request.Context(), username, at)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(history, "query ok", nil)
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	res := response.NewResponder(c)
+	user, err := h.service.Detail(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(user, "query ok", nil)
+}
+
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.UserUpdateRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.Update(c.Request.Context(), actorFromContext(c), c.Param("id"), &req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(user, "user berhasil diperbarui", nil)
+}
+
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	res := response.NewResponder(c)
+	if err := h.service.Delete(c.Request.Context(), actorFromContext(c), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "user berhasil dihapus, masih bisa dipulihkan selama masa retensi", nil)
+}
+
+func (h *UserHandler) ChangeUserStatus(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.UserStatusRequest
+	if !h.valid.ValigoJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.ChangeStatus(c.Request.Context(), actorFromContext(c), c.Param("id"), &req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(user, "status user berhasil diubah", nil)
+}
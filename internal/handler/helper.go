@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/password"
+	"github.com/gogaruda/valigo"
+)
+
+// handlePasswordError menampilkan pelanggaran kebijakan password dengan format validasi valigo,
+// error lain diteruskan ke apperror
+func handlePasswordError(c *gin.Context, v *valigo.Valigo, input valigo.Sanitizable, field string, err error) {
+	var perr *password.PolicyError
+	if errors.As(err, &perr) {
+		v.ValigoBusiness(c, input, perr.FieldErrors(field))
+		return
+	}
+
+	apperror.HandleHTTPError(c, err)
+}
+
+// actorFromContext mengambil identitas admin yang diset AuthMiddleware
+func actorFromContext(c *gin.Context) service.Actor {
+	roles, _ := c.Get("roles")
+	actor := service.Actor{ID: c.GetString("user_id")}
+	actor.Roles, _ = roles.([]string)
+	return actor
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/service"
 	"github.com/gogaruda/auth/pkg/response"
 	"github.com/golang-jwt/jwt/v5"
 	"strings"
@@ -38,6 +40,7 @@ func (m *middleware) AuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
+			m.checkCanaryToken(c, tokenStr)
 			res.Unauthorized("token tidak valid atau sudah kadaluarsa")
 			return
 		}
@@ -67,16 +70,30 @@ func (m *middleware) AuthMiddleware() gin.HandlerFunc {
 		}
 
 		var user model.UserModel
-		if err := m.db.QueryRow(`SELECT token_version FROM users WHERE id = ?`, userID).Scan(&user.TokenVersion); err != nil {
+		if err := m.db.QueryRow(`SELECT token_version, is_canary, status, status_reason, suspended_until FROM users WHERE id = ?`, userID).
+			Scan(&user.TokenVersion, &user.IsCanary, &user.Status, &user.StatusReason, &user.SuspendedUntil); err != nil {
+			m.checkCanaryToken(c, tokenStr)
 			res.Unauthorized("user tidak ditemukan")
 			return
 		}
 
-		if *user.TokenVersion != tokenVersion {
+		if user.IsCanary {
+			m.canary.Trip(c.Request.Context(), service.CanaryTrip{Source: service.CanarySourceToken, UserID: userID})
+			res.Unauthorized("token tidak valid atau sudah kadaluarsa")
+			return
+		}
+
+		if user.TokenVersion == nil || *user.TokenVersion != tokenVersion {
 			res.Unauthorized("token sudah tidak berlaku, silahkan login lagi!")
 			return
 		}
 
+		if err := service.CheckAccountStatus(&user, time.Now()); err != nil {
+			apperror.HandleHTTPError(c, err)
+			c.Abort()
+			return
+		}
+
 		rolesInterface, ok := claims["roles"].([]interface{})
 		if !ok {
 			res.Unauthorized("format roles dalam token tidak valid")
@@ -93,10 +110,21 @@ func (m *middleware) AuthMiddleware() gin.HandlerFunc {
 			roles = append(roles, roleStr)
 		}
 
+		scope, _ := claims["scope"].(string)
+
 		c.Set("user_id", userID)
 		c.Set("is_verified", claims["is_verified"].(bool))
 		c.Set("roles", roles)
+		c.Set("token_scope", scope)
 
 		c.Next()
 	}
 }
+
+// checkCanaryToken hanya dijalankan pada token yang ditolak, sehingga request normal
+// tidak menambah query
+func (m *middleware) checkCanaryToken(c *gin.Context, token string) {
+	if cred := m.canary.MatchToken(c.Request.Context(), token); cred != nil {
+		m.canary.Trip(c.Request.Context(), service.CanaryTrip{Source: service.CanarySourceToken, Credential: cred})
+	}
+}
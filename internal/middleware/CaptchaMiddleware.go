@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/response"
+	"log"
+)
+
+type CaptchaMode int
+
+const (
+	// CaptchaAlways mewajibkan captcha pada setiap request
+	CaptchaAlways CaptchaMode = iota
+	// CaptchaAfterFailures mewajibkan captcha setelah IP gagal login beberapa kali
+	CaptchaAfterFailures
+)
+
+const captchaHeader = "X-Captcha-Token"
+
+func (m *middleware) CaptchaMiddleware(mode CaptchaMode) gin.HandlerFunc {
+	if m.captcha == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		res := response.NewResponder(c)
+		ctx := c.Request.Context()
+		failKey := "captcha:login-fail:" + c.ClientIP()
+
+		required := mode == CaptchaAlways
+		if mode == CaptchaAfterFailures {
+			failures, err := m.counter.Get(ctx, failKey)
+			if err != nil {
+				log.Printf("captcha: gagal membaca hitungan gagal login: %v", err)
+			}
+			required = m.capCfg.LoginAfterFailures >= 0 && failures >= int64(m.capCfg.LoginAfterFailures)
+		}
+
+		if required {
+			token := c.GetHeader(captchaHeader)
+			if token == "" {
+				res.BadRequest(map[string]string{"captcha": "token captcha wajib dikirim lewat header " + captchaHeader}, "captcha wajib diisi")
+				return
+			}
+
+			ok, err := m.captcha.Verify(ctx, token, c.ClientIP())
+			if err != nil {
+				log.Printf("captcha: %v", err)
+				res.ServerError("gagal memverifikasi captcha, coba lagi")
+				return
+			}
+			if !ok {
+				res.BadRequest(map[string]string{"captcha": "captcha tidak valid"}, "verifikasi captcha gagal")
+				return
+			}
+		}
+
+		c.Next()
+
+		if mode == CaptchaAfterFailures && isLoginFailure(c) {
+			if _, err := m.counter.Incr(ctx, failKey, m.capCfg.FailureWindow); err != nil {
+				log.Printf("captcha: gagal mencatat gagal login: %v", err)
+			}
+		}
+	}
+}
+
+// isLoginFailure membaca kode error yang dicatat handler login di context, bukan status HTTP,
+// karena kode OTP salah (400) dan login ditunda (429) juga termasuk gagal login
+func isLoginFailure(c *gin.Context) bool {
+	last := c.Errors.Last()
+	return last != nil && service.IsLoginFailure(last.Err)
+}
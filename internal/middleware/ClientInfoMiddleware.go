@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/auth/pkg/clientinfo"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sensitiveHeaders tidak disimpan apa adanya, hanya panjang dan sidik jarinya
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Api-Key":     true,
+}
+
+func (m *middleware) ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info := clientinfo.Info{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Query:     c.Request.URL.RawQuery,
+			Headers:   headerFields(c.Request.Header),
+			Time:      time.Now(),
+		}
+
+		c.Request = c.Request.WithContext(clientinfo.WithInfo(c.Request.Context(), info))
+		c.Next()
+	}
+}
+
+func headerFields(h http.Header) map[string]string {
+	fields := make(map[string]string, len(h))
+	for name, values := range h {
+		val := strings.Join(values, ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			sum := sha256.Sum256([]byte(val))
+			val = fmt.Sprintf("[redacted len=%d sha256=%s]", len(val), hex.EncodeToString(sum[:])[:12])
+		}
+		fields[name] = val
+	}
+	return fields
+}
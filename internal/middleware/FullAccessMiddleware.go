@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/auth/pkg/response"
+)
+
+// FullAccessMiddleware menolak token terbatas (misalnya token wajib ganti password)
+func (m *middleware) FullAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res := response.NewResponder(c)
+		if scope := c.GetString("token_scope"); scope != "" {
+			res.Forbidden("password harus diganti terlebih dahulu")
+			return
+		}
+
+		c.Next()
+	}
+}
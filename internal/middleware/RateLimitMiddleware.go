@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/pkg/ratelimit"
+	"github.com/gogaruda/auth/pkg/response"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type RateLimitRoute string
+
+const (
+	RateLimitLogin             RateLimitRoute = "login"
+	RateLimitPhoneLogin        RateLimitRoute = "login-phone"
+	RateLimitRegister          RateLimitRoute = "register"
+	RateLimitEmailVerification RateLimitRoute = "email-verification"
+	RateLimitGoogleCallback    RateLimitRoute = "google-callback"
+	RateLimitPasswordForgot    RateLimitRoute = "password-forgot"
+	RateLimitPasswordReset     RateLimitRoute = "password-reset"
+	RateLimitAccountLink       RateLimitRoute = "account-link"
+)
+
+// maxIdentifierBody membatasi body yang dibaca untuk mencari identifier
+const maxIdentifierBody = 64 << 10
+
+type identifierKind int
+
+const (
+	// identifierText dibandingkan tanpa membedakan huruf besar kecil, untuk username dan email
+	identifierText identifierKind = iota
+	// identifierPhone dinormalisasi ke E.164 seperti di PhoneService
+	identifierPhone
+)
+
+type identifierField struct {
+	name string
+	kind identifierKind
+}
+
+// identifierFields adalah satu-satunya field body JSON yang menjadi identifier tiap route, sama dengan
+// field yang dipakai handler. Route tanpa entri dihitung per user_id (jika login) atau IP.
+var identifierFields = map[RateLimitRoute]identifierField{
+	RateLimitLogin:          {name: "identifier", kind: identifierText},
+	RateLimitPhoneLogin:     {name: "phone", kind: identifierPhone},
+	RateLimitRegister:       {name: "email", kind: identifierText},
+	RateLimitPasswordForgot: {name: "email", kind: identifierText},
+}
+
+func (m *middleware) RateLimitMiddleware(route RateLimitRoute) gin.HandlerFunc {
+	rule := m.rateRule(route)
+	if !m.rateCfg.Enabled || rule.Limit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	limit := ratelimit.Rule{Limit: rule.Limit, Window: rule.Window}
+	return func(c *gin.Context) {
+		res := response.NewResponder(c)
+		keys, err := m.rateLimitKeys(c, route, rule.KeyBy)
+		if err != nil {
+			// identifier yang tidak terbaca tidak boleh jatuh ke kunci IP saja
+			res.BadRequest(nil, err.Error())
+			return
+		}
+
+		for _, key := range keys {
+			result, err := m.limiter.Allow(c.Request.Context(), key, limit)
+			if err != nil {
+				// store bermasalah tidak boleh menutup akses login
+				log.Printf("rate limit %s: %v", route, err)
+				continue
+			}
+
+			c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			if !result.Allowed {
+				retry := int(math.Ceil(result.RetryAfter.Seconds()))
+				c.Header("X-RateLimit-Remaining", "0")
+				res.TooManyRequests("terlalu banyak permintaan, coba lagi dalam "+strconv.Itoa(retry)+" detik", retry)
+				return
+			}
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		}
+
+		c.Next()
+	}
+}
+
+func (m *middleware) rateRule(route RateLimitRoute) config.RateLimitRule {
+	switch route {
+	case RateLimitLogin, RateLimitPhoneLogin:
+		return m.rateCfg.Login
+	case RateLimitRegister:
+		return m.rateCfg.Register
+	case RateLimitEmailVerification:
+		return m.rateCfg.EmailVerification
+	case RateLimitGoogleCallback:
+		return m.rateCfg.GoogleCallback
+	case RateLimitPasswordForgot:
+		return m.rateCfg.PasswordForgot
+	case RateLimitPasswordReset:
+		return m.rateCfg.PasswordReset
+	case RateLimitAccountLink:
+		return m.rateCfg.AccountLink
+	}
+	return config.RateLimitRule{}
+}
+
+// rateLimitKeys menyusun kunci counter. Route dengan field identifier wajib mengirimnya,
+// route lain memakai user_id jika ada dan jatuh ke IP jika tidak
+func (m *middleware) rateLimitKeys(c *gin.Context, route RateLimitRoute, keyBy string) ([]string, error) {
+	ipKey := "rl:" + string(route) + ":ip:" + c.ClientIP()
+	if keyBy == config.RateLimitByIP {
+		return []string{ipKey}, nil
+	}
+
+	var identifier string
+	if field, ok := identifierFields[route]; ok {
+		var err error
+		if identifier, err = m.requestIdentifier(c, field); err != nil {
+			return nil, err
+		}
+	} else if identifier = c.GetString("user_id"); identifier == "" {
+		return []string{ipKey}, nil
+	}
+
+	idKey := "rl:" + string(route) + ":id:" + identifier
+	if keyBy == config.RateLimitByIdentifier {
+		return []string{idKey}, nil
+	}
+	return []string{ipKey, idKey}, nil
+}
+
+// requestIdentifier membaca field identifier dari body JSON tanpa menghabiskan body,
+// sehingga handler tetap bisa membacanya, lalu menormalkannya seperti service
+func (m *middleware) requestIdentifier(c *gin.Context, field identifierField) (string, error) {
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return "", errors.New("body harus berupa JSON")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdentifierBody+1))
+	if err != nil {
+		return "", errors.New("gagal membaca body")
+	}
+	if len(body) > maxIdentifierBody {
+		return "", errors.New("body terlalu besar")
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", errors.New("body JSON tidak valid")
+	}
+
+	val, _ := fields[field.name].(string)
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return "", fmt.Errorf("%s wajib diisi", field.name)
+	}
+
+	if field.kind == identifierPhone {
+		phone, err := m.ut.NormalizePhone(val)
+		if err != nil {
+			return "", err
+		}
+		return phone, nil
+	}
+	return strings.ToLower(val), nil
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+)
+
+// UserVisibleMiddleware menjaga endpoint /users/:id, user yang tersembunyi dari role
+// pengakses dijawab tidak ditemukan
+func (m *middleware) UserVisibleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, _ := c.Get("roles")
+		userRoles, _ := roles.([]string)
+
+		if err := m.visible.Check(c.Request.Context(), userRoles, c.Param("id")); err != nil {
+			apperror.HandleHTTPError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"database/sql"
 	"github.com/gin-gonic/gin"
 	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/service"
+	"github.com/gogaruda/auth/pkg/captcha"
+	"github.com/gogaruda/auth/pkg/ratelimit"
+	"github.com/gogaruda/auth/pkg/utils"
 )
 
 type Middleware interface {
@@ -11,14 +15,42 @@ type Middleware interface {
 	CORSMiddleware() gin.HandlerFunc
 	RoleMiddleware(matchType RoleMatchType, requiredRoles ...string) gin.HandlerFunc
 	EmailVerifiedMiddleware() gin.HandlerFunc
+	FullAccessMiddleware() gin.HandlerFunc
+	RateLimitMiddleware(route RateLimitRoute) gin.HandlerFunc
+	ClientInfoMiddleware() gin.HandlerFunc
+	CaptchaMiddleware(mode CaptchaMode) gin.HandlerFunc
+	UserVisibleMiddleware() gin.HandlerFunc
 }
 
 type middleware struct {
 	db      *sql.DB
 	cfg     config.JWTConfig
 	corsCfg config.CORSConfig
+	rateCfg config.RateLimitConfig
+	limiter ratelimit.Limiter
+	counter ratelimit.Store
+	canary  service.CanaryService
+	captcha captcha.Verifier
+	capCfg  config.CaptchaConfig
+	visible service.VisibilityService
+	ut      utils.Utils
 }
 
-func NewMiddleware(d *sql.DB, c config.JWTConfig, cc config.CORSConfig) Middleware {
-	return &middleware{db: d, cfg: c, corsCfg: cc}
+func NewMiddleware(
+	d *sql.DB,
+	c config.JWTConfig,
+	cc config.CORSConfig,
+	rc config.RateLimitConfig,
+	l ratelimit.Limiter,
+	st ratelimit.Store,
+	cs service.CanaryService,
+	cv captcha.Verifier,
+	cpc config.CaptchaConfig,
+	vs service.VisibilityService,
+	u utils.Utils,
+) Middleware {
+	return &middleware{
+		db: d, cfg: c, corsCfg: cc, rateCfg: rc, limiter: l, counter: st,
+		canary: cs, captcha: cv, capCfg: cpc, visible: vs, ut: u,
+	}
 }
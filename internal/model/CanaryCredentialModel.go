@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+const (
+	CanaryKindIdentifier = "identifier"
+	CanaryKindToken      = "token"
+)
+
+// CanaryCredentialModel adalah kredensial umpan yang tidak pernah dipakai secara sah.
+// Untuk kind token, Value berisi hash token-nya
+type CanaryCredentialModel struct {
+	ID              string
+	Kind            string
+	Value           string
+	Label           string
+	CreatedBy       *string
+	LastTriggeredAt *time.Time
+	CreatedAt       time.Time
+}
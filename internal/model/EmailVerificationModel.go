@@ -3,10 +3,13 @@ package model
 import "time"
 
 type EmailVerificationModel struct {
-	ID        string
-	UserID    string
-	Token     string
-	ExpiresAt time.Time
-	IsUsed    bool
-	CreatedAt time.Time
+	ID          string
+	UserID      string
+	Token       string
+	Code        *string
+	Attempts    int
+	LockedUntil *time.Time
+	ExpiresAt   time.Time
+	IsUsed      bool
+	CreatedAt   time.Time
 }
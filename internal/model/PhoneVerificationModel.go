@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	PhonePurposeVerify = "verify"
+	PhonePurposeLogin  = "login"
+)
+
+type PhoneVerificationModel struct {
+	ID          string
+	UserID      string
+	Phone       string
+	Purpose     string
+	Code        string
+	Attempts    int
+	LockedUntil *time.Time
+	ExpiresAt   time.Time
+	IsUsed      bool
+	CreatedAt   time.Time
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// UserDeviceModel adalah perangkat yang pernah dipakai login, dikenali dari Fingerprint
+type UserDeviceModel struct {
+	ID          string
+	UserID      string
+	Fingerprint string
+	IP          string
+	UserAgent   string
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
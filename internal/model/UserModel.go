@@ -1,14 +1,49 @@
 package model
 
+import "time"
+
 type UserModel struct {
 	ID             string
 	Username       *string
 	Email          string
+	Phone          *string
 	Password       *string
 	TokenVersion   *string
 	GoogleID       *string
 	IsVerified     bool
 	CreatedByAdmin bool
+	// IsCanary menandai akun umpan, setiap percobaan login memicu alert
+	IsCanary bool
+	// MustChangePassword aktif untuk akun buatan admin dan setelah admin reset password
+	MustChangePassword bool
+	PasswordChangedAt  *time.Time
+	// status penguncian karena gagal login
+	FailedLoginAttempts int
+	LastFailedLoginAt   *time.Time
+	LockedUntil         *time.Time
+	// status akun: active, suspended, deactivated atau deleted (soft delete)
+	Status         string
+	StatusReason   *string
+	SuspendedUntil *time.Time
+	DeletedAt      *time.Time
 	Roles          []RoleModel
 	Profile        *ProfileModel
 }
+
+const (
+	UserStatusActive      = "active"
+	UserStatusSuspended   = "suspended"
+	UserStatusDeactivated = "deactivated"
+	UserStatusDeleted     = "deleted"
+)
+
+// EffectiveStatus menganggap suspend yang sudah lewat tanggal berakhirnya sebagai aktif
+func (u *UserModel) EffectiveStatus(now time.Time) string {
+	if u.Status == "" {
+		return UserStatusActive
+	}
+	if u.Status == UserStatusSuspended && u.SuspendedUntil != nil && !now.Before(*u.SuspendedUntil) {
+		return UserStatusActive
+	}
+	return u.Status
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeAccountUnlock = "account_unlock"
+	TokenPurposeSessionRevoke = "session_revoke"
+	TokenPurposeLoginConfirm  = "login_confirm"
+	TokenPurposeEmailChange   = "email_change"
+	TokenPurposeEmailRevert   = "email_revert"
+)
+
+// UserTokenModel adalah token sekali pakai yang dikirim lewat email, Token berisi hash-nya
+type UserTokenModel struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	Token     string
+	Payload   *string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// UsernameHistoryModel mencatat pemegang sebuah username. Username tidak pernah dihapus
+// dari tabel ini sehingga tetap tercadang walau sudah diganti.
+type UsernameHistoryModel struct {
+	Username string
+	// UserID kosong untuk data lama yang tidak bisa dilacak pemiliknya
+	UserID    *string
+	UsedFrom  *time.Time
+	UsedUntil *time.Time
+}
@@ -3,18 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/model"
 	"github.com/gogaruda/dbtx"
+	"time"
 )
 
 type AuthRepository interface {
 	IsUsernameExists(ctx context.Context, username string) (bool, error)
 	IsEmailExists(ctx context.Context, email string) (bool, error)
+	IsPhoneExists(ctx context.Context, phone string) (bool, error)
 	Create(ctx context.Context, user model.UserModel) error
 	Identifier(ctx context.Context, identifier string) (*model.UserModel, error)
+	LoginByID(ctx context.Context, userID string) (*model.UserModel, error)
 	UpdateTokenVersion(userID, newVersion string) error
+	UpdatePassword(ctx context.Context, userID, hash string) error
+	ReplacePassword(ctx context.Context, userID, hash string, keepHistory int, mustChange bool) error
+	PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+	RecordLoginFailure(ctx context.Context, userID string, now time.Time, threshold int, lockFor time.Duration) (int, *time.Time, error)
+	ResetLoginFailures(ctx context.Context, userID string) error
 }
 
 type authRepository struct {
@@ -49,29 +58,55 @@ func (r *authRepository) IsEmailExists(ctx context.Context, email string) (bool,
 	return exists, nil
 }
 
+func (r *authRepository) IsPhoneExists(ctx context.Context, phone string) (bool, error) {
+	const query = `SELECT exists (SELECT 1 FROM phone_history WHERE phone = ?)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, phone).Scan(&exists)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "gagal memeriksa apakah nomor telepon sudah terdaftar di database", err)
+	}
+
+	return exists, nil
+}
+
 func (r *authRepository) Create(ctx context.Context, user model.UserModel) error {
 	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
 		_, err := tx.ExecContext(ctx, `INSERT INTO 
-			users(id, username, email, password, token_version, google_id, is_verified, created_by_admin) 
-			VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+			users(id, username, email, password, token_version, google_id, is_verified, created_by_admin, is_canary, must_change_password) 
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			user.ID, user.Username, user.Email, user.Password,
-			user.TokenVersion, user.GoogleID, user.IsVerified, user.CreatedByAdmin)
+			user.TokenVersion, user.GoogleID, user.IsVerified, user.CreatedByAdmin, user.IsCanary, user.MustChangePassword)
 		if err != nil {
 			return apperror.New(apperror.CodeDBError, "query insert users gagal", err)
 		}
 
 		if user.Username != nil {
-			_, err = tx.ExecContext(ctx, `INSERT INTO username_history(username) VALUES(?)`, user.Username)
+			_, err = tx.ExecContext(ctx, `INSERT INTO username_history(username, user_id, used_from) VALUES(?, ?, NOW())`,
+				user.Username, user.ID)
 			if err != nil {
 				return apperror.New(apperror.CodeDBError, "query insert username_history gagal", err)
 			}
 		}
 
-		_, err = tx.ExecContext(ctx, `INSERT INTO email_history(email) VALUES(?)`, user.Email)
+		_, err = tx.ExecContext(ctx, `INSERT INTO email_history(email, user_id) VALUES(?, ?)`, user.Email, user.ID)
 		if err != nil {
 			return apperror.New(apperror.CodeDBError, "query insert email_history gagal", err)
 		}
 
+		if user.Password != nil {
+			_, err = tx.ExecContext(ctx, `INSERT INTO password_history(user_id, password) VALUES(?, ?)`, user.ID, user.Password)
+			if err != nil {
+				return apperror.New(apperror.CodeDBError, "query insert password_history gagal", err)
+			}
+		}
+
+		if user.Profile != nil {
+			if err := insertProfile(ctx, tx, user.Profile); err != nil {
+				return err
+			}
+		}
+
 		stmt, err := tx.PrepareContext(ctx, `INSERT INTO user_roles(user_id, role_id) VALUES(?, ?)`)
 		if err != nil {
 			return apperror.New(apperror.CodeDBPrepareError, "gagal prepare insert user_roles", err)
@@ -91,13 +126,30 @@ func (r *authRepository) Create(ctx context.Context, user model.UserModel) error
 }
 
 func (r *authRepository) Identifier(ctx context.Context, identifier string) (*model.UserModel, error) {
+	return r.findForLogin(ctx, `username = ? OR email = ?`, identifier, identifier)
+}
+
+// LoginByID memuat data login yang sama dengan Identifier, dipakai saat login dilanjutkan
+// tanpa identifier (mis. konfirmasi perangkat baru)
+func (r *authRepository) LoginByID(ctx context.Context, userID string) (*model.UserModel, error) {
+	return r.findForLogin(ctx, `id = ?`, userID)
+}
+
+func (r *authRepository) findForLogin(ctx context.Context, where string, args ...any) (*model.UserModel, error) {
 	var user model.UserModel
 	var roles []model.RoleModel
 
 	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
-		err := tx.QueryRowContext(ctx, `SELECT id, password, is_verified FROM users WHERE username = ? OR email = ?`, identifier, identifier).
-			Scan(&user.ID, &user.Password, &user.IsVerified)
+		err := tx.QueryRowContext(ctx, `SELECT id, username, email, password, is_verified, is_canary, must_change_password, 
+			COALESCE(password_changed_at, created_at), failed_login_attempts, last_failed_login_at, locked_until, 
+			status, status_reason, suspended_until FROM users WHERE `+where, args...).
+			Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.IsVerified, &user.IsCanary, &user.MustChangePassword,
+				&user.PasswordChangedAt, &user.FailedLoginAttempts, &user.LastFailedLoginAt, &user.LockedUntil,
+				&user.Status, &user.StatusReason, &user.SuspendedUntil)
 		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", err)
+			}
 			return apperror.New(apperror.CodeDBError, "query select users gagal", err)
 		}
 
@@ -125,6 +177,9 @@ func (r *authRepository) Identifier(ctx context.Context, identifier string) (*mo
 	})
 
 	if err != nil {
+		if apperror.Is(err, apperror.CodeUserNotFound) {
+			return nil, err
+		}
 		return nil, apperror.New(apperror.CodeDBTxFailed, "gagal query context", err)
 	}
 
@@ -139,3 +194,114 @@ func (r *authRepository) UpdateTokenVersion(userID, newVersion string) error {
 
 	return nil
 }
+
+func (r *authRepository) UpdatePassword(ctx context.Context, userID, hash string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET password = ? WHERE id = ?`, hash, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update password gagal", err)
+	}
+
+	return nil
+}
+
+// ReplacePassword mengganti password dan mencatatnya di password_history,
+// riwayat dipangkas sehingga tersisa keepHistory hash terakhir
+func (r *authRepository) ReplacePassword(ctx context.Context, userID, hash string, keepHistory int, mustChange bool) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET password = ?, must_change_password = ?, password_changed_at = NOW() WHERE id = ?`,
+			hash, mustChange, userID)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query update password gagal", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO password_history(user_id, password) VALUES(?, ?)`, userID, hash)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query insert password_history gagal", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM password_history 
+			WHERE user_id = ? AND id NOT IN (
+				SELECT id FROM (
+					SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?
+				) AS latest
+			)`, userID, userID, keepHistory)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query prune password_history gagal", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *authRepository) PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT password FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select password_history gagal", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan password_history", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return hashes, nil
+}
+
+// RecordLoginFailure menambah hitungan gagal login di bawah row lock, sehingga percobaan paralel
+// tidak membaca hitungan yang sama. Mengembalikan hitungan baru dan batas kunci yang berlaku.
+func (r *authRepository) RecordLoginFailure(ctx context.Context, userID string, now time.Time, threshold int, lockFor time.Duration) (int, *time.Time, error) {
+	var attempts int
+	var lockedUntil *time.Time
+
+	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `SELECT failed_login_attempts, locked_until FROM users WHERE id = ? FOR UPDATE`, userID).
+			Scan(&attempts, &lockedUntil)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query select gagal login gagal", err)
+		}
+
+		// Hitungan dimulai ulang setelah masa kunci sebelumnya habis
+		if lockedUntil != nil && !now.Before(*lockedUntil) {
+			attempts, lockedUntil = 0, nil
+		}
+		attempts++
+
+		if lockedUntil == nil && threshold > 0 && attempts >= threshold {
+			t := now.Add(lockFor)
+			lockedUntil = &t
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE users SET failed_login_attempts = ?, last_failed_login_at = ?, locked_until = ? WHERE id = ?`,
+			attempts, now, lockedUntil, userID)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query update gagal login gagal", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return attempts, lockedUntil, nil
+}
+
+// ResetLoginFailures dipakai setelah login berhasil dan untuk membuka kunci akun
+func (r *authRepository) ResetLoginFailures(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET failed_login_attempts = 0, last_failed_login_at = NULL, locked_until = NULL WHERE id = ?`, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query reset gagal login gagal", err)
+	}
+
+	return nil
+}
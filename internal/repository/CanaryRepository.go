@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/dto/response"
+	"github.com/gogaruda/auth/internal/model"
+	"strings"
+)
+
+type CanaryRepository interface {
+	CreateCredential(ctx context.Context, c *model.CanaryCredentialModel) error
+	FindCredential(ctx context.Context, kind, value string) (*model.CanaryCredentialModel, error)
+	ListCredentials(ctx context.Context) ([]model.CanaryCredentialModel, error)
+	DeleteCredential(ctx context.Context, id string) error
+	MarkTriggered(ctx context.Context, id string) error
+	ListAccounts(ctx context.Context) ([]response.CanaryAccountResponse, error)
+}
+
+type canaryRepository struct {
+	db *sql.DB
+}
+
+func NewCanaryRepository(db *sql.DB) CanaryRepository {
+	return &canaryRepository{db: db}
+}
+
+func (r *canaryRepository) CreateCredential(ctx context.Context, c *model.CanaryCredentialModel) error {
+	query := `INSERT INTO canary_credentials (id, kind, value, label, created_by) VALUES(?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, c.ID, c.Kind, c.Value, c.Label, c.CreatedBy)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query insert canary_credentials gagal", err)
+	}
+	return nil
+}
+
+func (r *canaryRepository) FindCredential(ctx context.Context, kind, value string) (*model.CanaryCredentialModel, error) {
+	query := `SELECT id, kind, value, label, created_by, last_triggered_at, created_at 
+		FROM canary_credentials WHERE kind = ? AND value = ? LIMIT 1`
+
+	var c model.CanaryCredentialModel
+	err := r.db.QueryRowContext(ctx, query, kind, value).
+		Scan(&c.ID, &c.Kind, &c.Value, &c.Label, &c.CreatedBy, &c.LastTriggeredAt, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New(apperror.CodeResourceNotFound, "kredensial canary tidak ditemukan", err)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select canary_credentials gagal", err)
+	}
+
+	return &c, nil
+}
+
+func (r *canaryRepository) ListCredentials(ctx context.Context) ([]model.CanaryCredentialModel, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, kind, value, label, created_by, last_triggered_at, created_at 
+		FROM canary_credentials ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select canary_credentials gagal", err)
+	}
+	defer rows.Close()
+
+	var list []model.CanaryCredentialModel
+	for rows.Next() {
+		var c model.CanaryCredentialModel
+		if err := rows.Scan(&c.ID, &c.Kind, &c.Value, &c.Label, &c.CreatedBy, &c.LastTriggeredAt, &c.CreatedAt); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan canary_credentials", err)
+		}
+		list = append(list, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return list, nil
+}
+
+func (r *canaryRepository) DeleteCredential(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM canary_credentials WHERE id = ?`, id)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query delete canary_credentials gagal", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperror.New(apperror.CodeResourceNotFound, "kredensial canary tidak ditemukan", nil)
+	}
+	return nil
+}
+
+func (r *canaryRepository) MarkTriggered(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE canary_credentials SET last_triggered_at = NOW() WHERE id = ?`, id)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update canary_credentials gagal", err)
+	}
+	return nil
+}
+
+func (r *canaryRepository) ListAccounts(ctx context.Context) ([]response.CanaryAccountResponse, error) {
+	query := `SELECT u.id, u.username, u.email, GROUP_CONCAT(r.name ORDER BY r.name SEPARATOR ','), u.created_at
+		FROM users u
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		LEFT JOIN roles r ON r.id = ur.role_id
+		WHERE u.is_canary = TRUE
+		GROUP BY u.id, u.username, u.email, u.created_at
+		ORDER BY u.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select akun canary gagal", err)
+	}
+	defer rows.Close()
+
+	var list []response.CanaryAccountResponse
+	for rows.Next() {
+		var a response.CanaryAccountResponse
+		var roles sql.NullString
+		if err := rows.Scan(&a.ID, &a.Username, &a.Email, &roles, &a.CreatedAt); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan akun canary", err)
+		}
+		a.Roles = []string{}
+		if roles.Valid && roles.String != "" {
+			a.Roles = strings.Split(roles.String, ",")
+		}
+		list = append(list, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return list, nil
+}
@@ -5,11 +5,17 @@ import (
 	"database/sql"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/dbtx"
+	"time"
 )
 
 type EmailVerificationRepository interface {
 	Create(ctx context.Context, ev *model.EmailVerificationModel) error
 	FindByToken(ctx context.Context, token string) (*model.EmailVerificationModel, error)
+	FindLatestByUserID(ctx context.Context, userID string) (*model.EmailVerificationModel, error)
+	// ReserveAttempt memakai satu jatah percobaan secara atomik, 0 berarti jatah sudah habis
+	ReserveAttempt(ctx context.Context, id string, max int) (int, error)
+	Lock(ctx context.Context, id string, until time.Time) error
 	MarkAsUsed(ctx context.Context, id string) error
 }
 
@@ -22,8 +28,9 @@ func NewEmailVerificationRepository(db *sql.DB) EmailVerificationRepository {
 }
 
 func (r *emailVerificationRepository) Create(ctx context.Context, ev *model.EmailVerificationModel) error {
-	query := `INSERT INTO email_verifications (id, user_id, token, expires_at) VALUES(?, ?, ?, ?)`
-	_, err := r.db.ExecContext(ctx, query, ev.ID, ev.UserID, ev.Token, ev.ExpiresAt)
+	query := `INSERT INTO email_verifications (id, user_id, token, code, attempts, expires_at, created_at) 
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, ev.ID, ev.UserID, ev.Token, ev.Code, ev.Attempts, ev.ExpiresAt, ev.CreatedAt)
 	if err != nil {
 		return apperror.New(apperror.CodeDBError, "query insert email_verifications gagal", err)
 	}
@@ -46,6 +53,60 @@ func (r *emailVerificationRepository) FindByToken(ctx context.Context, token str
 	return &ev, nil
 }
 
+func (r *emailVerificationRepository) FindLatestByUserID(ctx context.Context, userID string) (*model.EmailVerificationModel, error) {
+	query := `SELECT id, user_id, code, attempts, locked_until, expires_at, is_used, created_at 
+		FROM email_verifications WHERE user_id = ? ORDER BY id DESC LIMIT 1`
+
+	var ev model.EmailVerificationModel
+	var code sql.NullString
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).
+		Scan(&ev.ID, &ev.UserID, &code, &ev.Attempts, &lockedUntil, &ev.ExpiresAt, &ev.IsUsed, &ev.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[CODE_NOT_FOUND]", "kode verifikasi tidak ditemukan", err, 404)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select email_verifications gagal", err)
+	}
+
+	if code.Valid {
+		ev.Code = &code.String
+	}
+	if lockedUntil.Valid {
+		ev.LockedUntil = &lockedUntil.Time
+	}
+
+	return &ev, nil
+}
+
+func (r *emailVerificationRepository) ReserveAttempt(ctx context.Context, id string, max int) (int, error) {
+	var attempts int
+	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `UPDATE email_verifications SET attempts = attempts + 1 WHERE id = ? AND attempts < ?`, id, max)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query update attempts gagal", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil
+		}
+
+		// baris masih terkunci oleh update di atas, jadi nilai ini milik percobaan ini
+		if err := tx.QueryRowContext(ctx, `SELECT attempts FROM email_verifications WHERE id = ?`, id).Scan(&attempts); err != nil {
+			return apperror.New(apperror.CodeDBError, "query select attempts gagal", err)
+		}
+		return nil
+	})
+	return attempts, err
+}
+
+func (r *emailVerificationRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE email_verifications SET locked_until = ? WHERE id = ?`, until, id)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update locked_until gagal", err)
+	}
+	return nil
+}
+
 func (r *emailVerificationRepository) MarkAsUsed(ctx context.Context, id string) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE email_verifications SET is_used = true WHERE id = ?`, id)
 	if err != nil {
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/dbtx"
+	"time"
+)
+
+type PhoneVerificationRepository interface {
+	Create(ctx context.Context, pv *model.PhoneVerificationModel) error
+	FindLatest(ctx context.Context, userID, purpose string) (*model.PhoneVerificationModel, error)
+	// ReserveAttempt memakai satu jatah percobaan secara atomik, 0 berarti jatah sudah habis
+	ReserveAttempt(ctx context.Context, id string, max int) (int, error)
+	Lock(ctx context.Context, id string, until time.Time) error
+	MarkAsUsed(ctx context.Context, id string) error
+}
+
+type phoneVerificationRepository struct {
+	db *sql.DB
+}
+
+func NewPhoneVerificationRepository(db *sql.DB) PhoneVerificationRepository {
+	return &phoneVerificationRepository{db: db}
+}
+
+func (r *phoneVerificationRepository) Create(ctx context.Context, pv *model.PhoneVerificationModel) error {
+	query := `INSERT INTO phone_verifications (id, user_id, phone, purpose, code, attempts, expires_at, created_at) 
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, pv.ID, pv.UserID, pv.Phone, pv.Purpose, pv.Code, pv.Attempts, pv.ExpiresAt, pv.CreatedAt)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query insert phone_verifications gagal", err)
+	}
+	return nil
+}
+
+func (r *phoneVerificationRepository) FindLatest(ctx context.Context, userID, purpose string) (*model.PhoneVerificationModel, error) {
+	query := `SELECT id, user_id, phone, purpose, code, attempts, locked_until, expires_at, is_used, created_at 
+		FROM phone_verifications WHERE user_id = ? AND purpose = ? ORDER BY id DESC LIMIT 1`
+
+	var pv model.PhoneVerificationModel
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID, purpose).
+		Scan(&pv.ID, &pv.UserID, &pv.Phone, &pv.Purpose, &pv.Code, &pv.Attempts, &lockedUntil, &pv.ExpiresAt, &pv.IsUsed, &pv.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[CODE_NOT_FOUND]", "kode verifikasi tidak ditemukan", err, 404)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select phone_verifications gagal", err)
+	}
+
+	if lockedUntil.Valid {
+		pv.LockedUntil = &lockedUntil.Time
+	}
+
+	return &pv, nil
+}
+
+func (r *phoneVerificationRepository) ReserveAttempt(ctx context.Context, id string, max int) (int, error) {
+	var attempts int
+	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `UPDATE phone_verifications SET attempts = attempts + 1 WHERE id = ? AND attempts < ?`, id, max)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query update attempts gagal", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil
+		}
+
+		// baris masih terkunci oleh update di atas, jadi nilai ini milik percobaan ini
+		if err := tx.QueryRowContext(ctx, `SELECT attempts FROM phone_verifications WHERE id = ?`, id).Scan(&attempts); err != nil {
+			return apperror.New(apperror.CodeDBError, "query select attempts gagal", err)
+		}
+		return nil
+	})
+	return attempts, err
+}
+
+func (r *phoneVerificationRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE phone_verifications SET locked_until = ? WHERE id = ?`, until, id)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update locked_until gagal", err)
+	}
+	return nil
+}
+
+func (r *phoneVerificationRepository) MarkAsUsed(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE phone_verifications SET is_used = true WHERE id = ?`, id)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update is_used gagal", err)
+	}
+	return nil
+}
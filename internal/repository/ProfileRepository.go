@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/model"
+)
+
+const insertProfileQuery = `INSERT INTO profiles (id, user_id, full_name, address, gender, image) VALUES(?, ?, ?, ?, ?, ?)`
+
+type ProfileRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*model.ProfileModel, error)
+	Create(ctx context.Context, profile *model.ProfileModel) error
+	Update(ctx context.Context, profile *model.ProfileModel) error
+}
+
+type profileRepository struct {
+	db *sql.DB
+}
+
+func NewProfileRepository(db *sql.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+func (r *profileRepository) FindByUserID(ctx context.Context, userID string) (*model.ProfileModel, error) {
+	var p model.ProfileModel
+	err := r.db.QueryRowContext(ctx, `SELECT id, user_id, full_name, address, gender, image FROM profiles WHERE user_id = ? LIMIT 1`, userID).
+		Scan(&p.ID, &p.UserID, &p.FullName, &p.Address, &p.Gender, &p.Image)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[PROFILE_NOT_FOUND]", "profil tidak ditemukan", err, 404)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select profiles gagal", err)
+	}
+
+	return &p, nil
+}
+
+func (r *profileRepository) Create(ctx context.Context, p *model.ProfileModel) error {
+	_, err := r.db.ExecContext(ctx, insertProfileQuery, p.ID, p.UserID, p.FullName, p.Address, p.Gender, p.Image)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return apperror.New("[PROFILE_CONFLICT]", "profil sudah ada", err, 409)
+		}
+		return apperror.New(apperror.CodeDBError, "query insert profiles gagal", err)
+	}
+
+	return nil
+}
+
+func (r *profileRepository) Update(ctx context.Context, p *model.ProfileModel) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE profiles SET full_name = ?, address = ?, gender = ?, image = ? WHERE user_id = ?`,
+		p.FullName, p.Address, p.Gender, p.Image, p.UserID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update profiles gagal", err)
+	}
+
+	return nil
+}
+
+// insertProfile dipakai saat membuat user agar profil tersimpan dalam transaksi yang sama
+func insertProfile(ctx context.Context, tx *sql.Tx, p *model.ProfileModel) error {
+	_, err := tx.ExecContext(ctx, insertProfileQuery, p.ID, p.UserID, p.FullName, p.Address, p.Gender, p.Image)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query insert profiles gagal", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/model"
+)
+
+type UserDeviceRepository interface {
+	Touch(ctx context.Context, d *model.UserDeviceModel) (bool, error)
+	CountByUser(ctx context.Context, userID string) (int, error)
+	FindByFingerprint(ctx context.Context, userID, fingerprint string) (*model.UserDeviceModel, error)
+	ListByUser(ctx context.Context, userID string) ([]model.UserDeviceModel, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type userDeviceRepository struct {
+	db *sql.DB
+}
+
+func NewUserDeviceRepository(db *sql.DB) UserDeviceRepository {
+	return &userDeviceRepository{db: db}
+}
+
+// Touch menyimpan perangkat baru atau memperbarui last_seen perangkat lama,
+// mengembalikan true jika perangkat baru saja ditambahkan
+func (r *userDeviceRepository) Touch(ctx context.Context, d *model.UserDeviceModel) (bool, error) {
+	query := `INSERT INTO user_devices (id, user_id, fingerprint, ip, user_agent) VALUES(?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE ip = VALUES(ip), user_agent = VALUES(user_agent), last_seen_at = NOW()`
+	result, err := r.db.ExecContext(ctx, query, d.ID, d.UserID, d.Fingerprint, d.IP, d.UserAgent)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "query upsert user_devices gagal", err)
+	}
+
+	// MySQL: 1 = baris baru, 2 = baris lama diperbarui, 0 = tidak ada perubahan
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "gagal membaca hasil upsert user_devices", err)
+	}
+	return n == 1, nil
+}
+
+func (r *userDeviceRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_devices WHERE user_id = ?`, userID).Scan(&total)
+	if err != nil {
+		return 0, apperror.New(apperror.CodeDBError, "query count user_devices gagal", err)
+	}
+	return total, nil
+}
+
+func (r *userDeviceRepository) FindByFingerprint(ctx context.Context, userID, fingerprint string) (*model.UserDeviceModel, error) {
+	query := `SELECT id, user_id, fingerprint, ip, user_agent, first_seen_at, last_seen_at 
+		FROM user_devices WHERE user_id = ? AND fingerprint = ? LIMIT 1`
+
+	var d model.UserDeviceModel
+	err := r.db.QueryRowContext(ctx, query, userID, fingerprint).
+		Scan(&d.ID, &d.UserID, &d.Fingerprint, &d.IP, &d.UserAgent, &d.FirstSeenAt, &d.LastSeenAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[DEVICE_NOT_FOUND]", "perangkat tidak ditemukan", err, 404)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select user_devices gagal", err)
+	}
+
+	return &d, nil
+}
+
+func (r *userDeviceRepository) ListByUser(ctx context.Context, userID string) ([]model.UserDeviceModel, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, fingerprint, ip, user_agent, first_seen_at, last_seen_at 
+		FROM user_devices WHERE user_id = ? ORDER BY last_seen_at DESC`, userID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select user_devices gagal", err)
+	}
+	defer rows.Close()
+
+	var devices []model.UserDeviceModel
+	for rows.Next() {
+		var d model.UserDeviceModel
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Fingerprint, &d.IP, &d.UserAgent, &d.FirstSeenAt, &d.LastSeenAt); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan user_devices", err)
+		}
+		devices = append(devices, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return devices, nil
+}
+
+func (r *userDeviceRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_devices WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query delete user_devices gagal", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperror.New("[DEVICE_NOT_FOUND]", "perangkat tidak ditemukan", nil, 404)
+	}
+	return nil
+}
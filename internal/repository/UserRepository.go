@@ -3,20 +3,35 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/dto/response"
 	"github.com/gogaruda/auth/internal/model"
 	"github.com/gogaruda/dbtx"
+	"strings"
+	"time"
 )
 
 type UserRepository interface {
-	GetAll(ctx context.Context, limit, offset int) ([]response.UserResponse, int, error)
+	GetAll(ctx context.Context, f UserFilter) ([]response.UserResponse, int, error)
+	GetAllKeyset(ctx context.Context, f UserFilter, k UserKeyset) ([]response.UserResponse, error)
 	Create(ctx context.Context, user model.UserModel) error
 	FindByEmail(ctx context.Context, email string) (*model.UserModel, error)
 	FindByID(ctx context.Context, userID string) (*model.UserModel, error)
+	FindByPhone(ctx context.Context, phone string) (*model.UserModel, error)
 	UpdateIsVerified(ctx context.Context, user *model.UserModel) error
 	UpdateGoogleID(ctx context.Context, userID, googleID string) error
+	UpdatePhone(ctx context.Context, userID, phone string) error
+	ChangeUsername(ctx context.Context, userID string, oldUsername *string, newUsername string) error
+	LastUsernameChange(ctx context.Context, userID string) (*time.Time, error)
+	FindUsernameHistory(ctx context.Context, username string) (*model.UsernameHistoryModel, error)
+	ChangeEmail(ctx context.Context, userID, email string) error
+	UpdateStatus(ctx context.Context, userID, status string, reason *string, until *time.Time, tokenVersion *string) error
+	FindDeletedBefore(ctx context.Context, before time.Time) ([]string, error)
+	Update(ctx context.Context, before, after *model.UserModel) error
+	Delete(ctx context.Context, userID string) error
 }
 
 type userRepository struct {
@@ -27,116 +42,330 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]response.UserResponse, int, error) {
-	var total int
-	queryCount := `
-				SELECT COUNT(DISTINCT u.id) AS total
-				FROM users u
-				JOIN user_roles ur ON u.id = ur.user_id
-				JOIN roles r ON r.id = ur.role_id
-				WHERE r.name NOT IN (?, ?)
-			`
-	err := r.db.QueryRowContext(ctx, queryCount, "super admin", "admin").Scan(&total)
+// UserFilter kriteria daftar user untuk admin, field kosong berarti tidak difilter
+type UserFilter struct {
+	// HiddenRoles user yang memiliki salah satu role ini tidak ikut tampil
+	HiddenRoles []string
+	// Search dicocokkan sebagian ke username, email dan nama lengkap
+	Search string
+	Role   string
+	// Status kosong menampilkan semua user kecuali yang dihapus
+	Status         string
+	IsVerified     *bool
+	CreatedByAdmin *bool
+	Google         *bool
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	// Sort salah satu kunci userSortColumns, awalan "-" untuk urutan menurun
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// UserKeyset posisi halaman pada pagination cursor, urutan selalu berdasarkan ULID (waktu dibuat)
+type UserKeyset struct {
+	// ID user batas halaman, kosong untuk halaman pertama
+	ID string
+	// Desc urutan tampilan, true berarti user terbaru lebih dulu
+	Desc bool
+	// Backward mengambil halaman sebelum ID, bukan sesudahnya
+	Backward bool
+}
+
+// userSortColumns whitelist kolom yang boleh dipakai untuk ORDER BY
+var userSortColumns = map[string]string{
+	"created_at": "u.created_at",
+	"updated_at": "u.updated_at",
+	"username":   "u.username",
+	"email":      "u.email",
+	"full_name":  "p.full_name",
+}
+
+const defaultUserSort = "-updated_at"
+
+// GetAll memilih user dulu baru memuat role-nya, sehingga LIMIT berlaku per user dan
+// COUNT memakai predikat yang sama persis dengan halaman data
+func (r *userRepository) GetAll(ctx context.Context, f UserFilter) ([]response.UserResponse, int, error) {
+	orderBy, err := userOrderBy(f.Sort)
 	if err != nil {
+		return nil, 0, err
+	}
+
+	where, args := userFilterWhere(f)
+	from := ` FROM users u LEFT JOIN profiles p ON p.user_id = u.id WHERE ` + where
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
 		return nil, 0, apperror.New(apperror.CodeDBError, "query count users gagal", err)
 	}
 
-	queryUsers := `
+	users, err := r.queryUsers(ctx, from+` ORDER BY `+orderBy+` LIMIT ? OFFSET ?`, append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// GetAllKeyset mengambil satu halaman berdasarkan posisi ULID tanpa OFFSET dan tanpa COUNT,
+// sehingga tetap cepat dan stabil walaupun ada user baru di antara dua halaman.
+// f.Sort dan f.Offset diabaikan, urutan selalu u.id.
+func (r *userRepository) GetAllKeyset(ctx context.Context, f UserFilter, k UserKeyset) ([]response.UserResponse, error) {
+	where, args := userFilterWhere(f)
+
+	// halaman sebelumnya dibaca dengan arah kebalikan lalu dibalik lagi
+	desc := k.Desc != k.Backward
+	dir, op := "ASC", ">"
+	if desc {
+		dir, op = "DESC", "<"
+	}
+
+	if k.ID != "" {
+		where += " AND u.id " + op + " ?"
+		args = append(args, k.ID)
+	}
+
+	users, err := r.queryUsers(ctx, ` FROM users u LEFT JOIN profiles p ON p.user_id = u.id WHERE `+where+
+		` ORDER BY u.id `+dir+` LIMIT ?`, append(args, f.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	return users, nil
+}
+
+// queryUsers menjalankan SELECT kolom daftar user dengan FROM/WHERE/ORDER yang disusun pemanggil
+func (r *userRepository) queryUsers(ctx context.Context, rest string, args ...any) ([]response.UserResponse, error) {
+	query := `
 		SELECT 
 			u.id, u.username, u.email, u.google_id, u.is_verified, u.created_by_admin,
-			r.id AS role_id, r.name AS role_name
-		FROM users u 
-		JOIN user_roles ur ON u.id = ur.user_id
-		JOIN roles r ON r.id = ur.role_id
-		WHERE u.id NOT IN (
-			SELECT ur.user_id 
-			FROM user_roles ur
-			JOIN roles r ON r.id = ur.role_id
-			WHERE r.name IN (?,?)
-		)
-		ORDER BY u.updated_at DESC
-		LIMIT ? OFFSET ?
-	`
+			u.failed_login_attempts, u.locked_until, u.status, u.status_reason, u.suspended_until,
+			p.full_name, p.address, p.gender, p.image` + rest
 
-	rows, err := r.db.QueryContext(ctx, queryUsers, "super admin", "admin", limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, apperror.New(apperror.CodeDBError, "query select users gagal", err)
+		return nil, apperror.New(apperror.CodeDBError, "query select users gagal", err)
 	}
 	defer rows.Close()
 
-	userMap := make(map[string]*response.UserResponse)
-	var orderedIDs []string
+	users := []response.UserResponse{}
+	index := make(map[string]int)
 
 	for rows.Next() {
 		var (
-			id, roleID, roleName       string
-			username, googleID         sql.NullString
-			email                      string
-			isVerified, createdByAdmin bool
+			user                   response.UserResponse
+			lockedUntil            sql.NullTime
+			fullName               sql.NullString
+			address, gender, image *string
 		)
 
-		if err := rows.Scan(&id, &username, &email, &googleID, &isVerified, &createdByAdmin, &roleID, &roleName); err != nil {
-			return nil, 0, apperror.New(apperror.CodeDBError, "gagal scan users", err)
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.GoogleID, &user.IsVerified, &user.CreatedByAdmin,
+			&user.FailedLogins, &lockedUntil, &user.Status, &user.StatusReason, &user.SuspendedUntil,
+			&fullName, &address, &gender, &image); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan users", err)
 		}
 
-		user, exists := userMap[id]
-		if !exists {
-			user = &response.UserResponse{
-				ID:             id,
-				Email:          email,
-				IsVerified:     isVerified,
-				CreatedByAdmin: createdByAdmin,
-				Roles:          []response.RoleResponse{},
-			}
+		user.Roles = []response.RoleResponse{}
+		if lockedUntil.Valid && lockedUntil.Time.After(time.Now()) {
+			user.IsLocked = true
+			user.LockedUntil = &lockedUntil.Time
+		}
 
-			if username.Valid {
-				user.Username = &username.String
-			}
-			if googleID.Valid {
-				user.GoogleID = &googleID.String
+		if fullName.Valid {
+			user.Profile = &response.ProfileResponse{
+				FullName: fullName.String,
+				Address:  address,
+				Gender:   gender,
+				Image:    image,
 			}
+		}
+
+		index[user.ID] = len(users)
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	if err := r.attachRoles(ctx, users, index); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// attachRoles memuat role untuk satu halaman user sekaligus
+func (r *userRepository) attachRoles(ctx context.Context, users []response.UserResponse, index map[string]int) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(users))
+	args := make([]any, len(users))
+	for i, u := range users {
+		placeholders[i], args[i] = "?", u.ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT ur.user_id, r.id, r.name FROM user_roles ur 
+		JOIN roles r ON r.id = ur.role_id WHERE ur.user_id IN (`+strings.Join(placeholders, ",")+`)`, args...)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query select roles gagal", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var role response.RoleResponse
+		if err := rows.Scan(&userID, &role.ID, &role.Name); err != nil {
+			return apperror.New(apperror.CodeDBError, "gagal scan roles", err)
+		}
+		users[index[userID]].Roles = append(users[index[userID]].Roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+	return nil
+}
 
-			userMap[id] = user
-			orderedIDs = append(orderedIDs, id)
+// userFilterWhere menyusun predikat WHERE yang dipakai bersama oleh COUNT dan query halaman
+func userFilterWhere(f UserFilter) (string, []any) {
+	conds := []string{"u.is_canary = FALSE"}
+	var args []any
+
+	if len(f.HiddenRoles) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(f.HiddenRoles)), ",")
+		conds = append(conds, `NOT EXISTS (SELECT 1 FROM user_roles xr JOIN roles r ON r.id = xr.role_id 
+			WHERE xr.user_id = u.id AND r.name IN (`+placeholders+`))`)
+		for _, role := range f.HiddenRoles {
+			args = append(args, role)
 		}
+	}
 
-		user.Roles = append(user.Roles, response.RoleResponse{
-			ID:   roleID,
-			Name: roleName,
-		})
+	// suspend yang sudah berakhir dihitung active, sama seperti model.UserModel.EffectiveStatus
+	switch f.Status {
+	case "":
+		conds = append(conds, "u.status <> 'deleted'")
+	case model.UserStatusActive:
+		conds = append(conds, "(u.status = 'active' OR (u.status = 'suspended' AND u.suspended_until <= NOW()))")
+	case model.UserStatusSuspended:
+		conds = append(conds, "u.status = 'suspended' AND (u.suspended_until IS NULL OR u.suspended_until > NOW())")
+	default:
+		conds = append(conds, "u.status = ?")
+		args = append(args, f.Status)
 	}
 
-	var users []response.UserResponse
-	for _, id := range orderedIDs {
-		users = append(users, *userMap[id])
+	if f.Search != "" {
+		like := "%" + escapeLike(f.Search) + "%"
+		conds = append(conds, "(u.username LIKE ? OR u.email LIKE ? OR p.full_name LIKE ?)")
+		args = append(args, like, like, like)
 	}
 
-	return users, total, nil
+	if f.Role != "" {
+		conds = append(conds, `EXISTS (SELECT 1 FROM user_roles fr JOIN roles r ON r.id = fr.role_id 
+			WHERE fr.user_id = u.id AND r.name = ?)`)
+		args = append(args, f.Role)
+	}
+
+	if f.IsVerified != nil {
+		conds = append(conds, "u.is_verified = ?")
+		args = append(args, *f.IsVerified)
+	}
+
+	if f.CreatedByAdmin != nil {
+		conds = append(conds, "u.created_by_admin = ?")
+		args = append(args, *f.CreatedByAdmin)
+	}
+
+	if f.Google != nil {
+		if *f.Google {
+			conds = append(conds, "u.google_id IS NOT NULL")
+		} else {
+			conds = append(conds, "u.google_id IS NULL")
+		}
+	}
+
+	if f.CreatedFrom != nil {
+		conds = append(conds, "u.created_at >= ?")
+		args = append(args, *f.CreatedFrom)
+	}
+
+	if f.CreatedTo != nil {
+		conds = append(conds, "u.created_at < ?")
+		args = append(args, *f.CreatedTo)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// userOrderBy menerjemahkan sort dari query string lewat whitelist, u.id sebagai pemecah seri
+func userOrderBy(sort string) (string, error) {
+	if sort == "" {
+		sort = defaultUserSort
+	}
+
+	dir := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		dir, sort = "DESC", sort[1:]
+	}
+
+	col, ok := userSortColumns[sort]
+	if !ok {
+		return "", apperror.New(apperror.CodeBadRequest, fmt.Sprintf("sort %q tidak didukung", sort), nil)
+	}
+
+	return fmt.Sprintf("%s %s, u.id %s", col, dir, dir), nil
+}
+
+// escapeLike supaya % dan _ dari input dicari sebagai karakter biasa
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 func (r *userRepository) Create(ctx context.Context, user model.UserModel) error {
 	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
 		_, err := tx.ExecContext(ctx, `INSERT INTO 
-			users(id, username, email, password, token_version, google_id, is_verified, created_by_admin) 
-			VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+			users(id, username, email, password, token_version, google_id, is_verified, created_by_admin, is_canary, must_change_password) 
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			user.ID, user.Username, user.Email, user.Password,
-			user.TokenVersion, user.GoogleID, user.IsVerified, user.CreatedByAdmin)
+			user.TokenVersion, user.GoogleID, user.IsVerified, user.CreatedByAdmin, user.IsCanary, user.MustChangePassword)
 		if err != nil {
 			return apperror.New(apperror.CodeDBError, "query insert users gagal", err)
 		}
 
 		if user.Username != nil {
-			_, err = tx.ExecContext(ctx, `INSERT INTO username_history(username) VALUES(?)`, user.Username)
+			_, err = tx.ExecContext(ctx, `INSERT INTO username_history(username, user_id, used_from) VALUES(?, ?, NOW())`,
+				user.Username, user.ID)
 			if err != nil {
 				return apperror.New(apperror.CodeDBError, "query insert username_history gagal", err)
 			}
 		}
 
-		_, err = tx.ExecContext(ctx, `INSERT INTO email_history(email) VALUES(?)`, user.Email)
+		_, err = tx.ExecContext(ctx, `INSERT INTO email_history(email, user_id) VALUES(?, ?)`, user.Email, user.ID)
 		if err != nil {
 			return apperror.New(apperror.CodeDBError, "query insert email_history gagal", err)
 		}
 
+		if user.Password != nil {
+			_, err = tx.ExecContext(ctx, `INSERT INTO password_history(user_id, password) VALUES(?, ?)`, user.ID, user.Password)
+			if err != nil {
+				return apperror.New(apperror.CodeDBError, "query insert password_history gagal", err)
+			}
+		}
+
+		if user.Profile != nil {
+			if err := insertProfile(ctx, tx, user.Profile); err != nil {
+				return err
+			}
+		}
+
 		stmt, err := tx.PrepareContext(ctx, `INSERT INTO user_roles(user_id, role_id) VALUES(?, ?)`)
 		if err != nil {
 			return apperror.New(apperror.CodeDBPrepareError, "gagal prepare insert user_roles", err)
@@ -156,12 +385,14 @@ func (r *userRepository) Create(ctx context.Context, user model.UserModel) error
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.UserModel, error) {
-	query := `SELECT id, username, email, password, google_id, is_verified, created_by_admin FROM users WHERE email = ?`
+	query := `SELECT id, username, email, password, google_id, is_verified, created_by_admin, is_canary, 
+		status, status_reason, suspended_until FROM users WHERE email = ?`
 	var user model.UserModel
 	var username, password, googleID sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &username, &user.Email, &password, &googleID, &user.IsVerified, &user.CreatedByAdmin,
+		&user.ID, &username, &user.Email, &password, &googleID, &user.IsVerified, &user.CreatedByAdmin, &user.IsCanary,
+		&user.Status, &user.StatusReason, &user.SuspendedUntil,
 	)
 
 	if err != nil {
@@ -202,8 +433,12 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*model.Us
 	var user model.UserModel
 	var tokenVersion sql.NullString
 	user.TokenVersion = &tokenVersion.String
-	err := r.db.QueryRowContext(ctx, `SELECT id, username, email, password, token_version, is_verified FROM users WHERE id = ? LIMIT 1`, userID).
-		Scan(&user.ID, &user.Username, &user.Email, &user.Password, &tokenVersion, &user.IsVerified)
+	err := r.db.QueryRowContext(ctx, `SELECT id, username, email, phone, password, token_version, google_id, is_verified, 
+		created_by_admin, is_canary, must_change_password, failed_login_attempts, locked_until, status, status_reason, suspended_until, 
+		deleted_at FROM users WHERE id = ? LIMIT 1`, userID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Phone, &user.Password, &tokenVersion, &user.GoogleID, &user.IsVerified,
+			&user.CreatedByAdmin, &user.IsCanary, &user.MustChangePassword, &user.FailedLoginAttempts, &user.LockedUntil,
+			&user.Status, &user.StatusReason, &user.SuspendedUntil, &user.DeletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", err)
@@ -211,6 +446,56 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*model.Us
 		return nil, apperror.New(apperror.CodeDBError, "query findbyid users gagal", err)
 	}
 
+	user.Roles, err = r.findRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) findRoles(ctx context.Context, userID string) ([]model.RoleModel, error) {
+	rolesQuery := `SELECT r.id, r.name FROM roles r INNER JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = ?`
+	rows, err := r.db.QueryContext(ctx, rolesQuery, userID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select roles gagal", err)
+	}
+	defer rows.Close()
+
+	var roles []model.RoleModel
+	for rows.Next() {
+		var role model.RoleModel
+		if err := rows.Scan(&role.ID, &role.Name); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan roles", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return roles, nil
+}
+
+func (r *userRepository) FindByPhone(ctx context.Context, phone string) (*model.UserModel, error) {
+	var user model.UserModel
+	err := r.db.QueryRowContext(ctx, `SELECT id, username, email, phone, is_verified, created_by_admin, is_canary, must_change_password, 
+		COALESCE(password_changed_at, created_at), status, status_reason, suspended_until FROM users WHERE phone = ? LIMIT 1`, phone).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Phone, &user.IsVerified, &user.CreatedByAdmin, &user.IsCanary,
+			&user.MustChangePassword, &user.PasswordChangedAt, &user.Status, &user.StatusReason, &user.SuspendedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", err)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query findbyphone users gagal", err)
+	}
+
+	user.Roles, err = r.findRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -230,3 +515,224 @@ func (r *userRepository) UpdateGoogleID(ctx context.Context, googleID, userID st
 	}
 	return nil
 }
+
+func (r *userRepository) UpdatePhone(ctx context.Context, userID, phone string) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET phone = ? WHERE id = ?`, phone, userID)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query update users phone gagal", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO phone_history(phone) VALUES(?)`, phone)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query insert phone_history gagal", err)
+		}
+
+		return nil
+	})
+}
+
+// ChangeUsername mengganti username dan menutup masa pakai username lama,
+// username lama tetap tersimpan di username_history sehingga tidak bisa dipakai lagi
+func (r *userRepository) ChangeUsername(ctx context.Context, userID string, oldUsername *string, newUsername string) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET username = ? WHERE id = ?`, newUsername, userID); err != nil {
+			return usernameWriteError("query update users username gagal", err)
+		}
+
+		if oldUsername != nil {
+			_, err := tx.ExecContext(ctx, `UPDATE username_history SET used_until = NOW(), user_id = COALESCE(user_id, ?) 
+				WHERE username = ? AND used_until IS NULL`, userID, *oldUsername)
+			if err != nil {
+				return apperror.New(apperror.CodeDBError, "query update username_history gagal", err)
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `INSERT INTO username_history(username, user_id, used_from) VALUES(?, ?, NOW())`, newUsername, userID)
+		if err != nil {
+			return usernameWriteError("query insert username_history gagal", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *userRepository) LastUsernameChange(ctx context.Context, userID string) (*time.Time, error) {
+	var last sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT MAX(used_until) FROM username_history WHERE user_id = ?`, userID).Scan(&last)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select username_history gagal", err)
+	}
+
+	if !last.Valid {
+		return nil, nil
+	}
+	return &last.Time, nil
+}
+
+func (r *userRepository) FindUsernameHistory(ctx context.Context, username string) (*model.UsernameHistoryModel, error) {
+	var h model.UsernameHistoryModel
+	err := r.db.QueryRowContext(ctx, `SELECT username, user_id, used_from, used_until FROM username_history WHERE username = ? LIMIT 1`, username).
+		Scan(&h.Username, &h.UserID, &h.UsedFrom, &h.UsedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[USERNAME_NOT_FOUND]", "username belum pernah dipakai", err, 404)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select username_history gagal", err)
+	}
+
+	return &h, nil
+}
+
+// usernameWriteError membedakan bentrok unique key (username dipakai bersamaan) dari error db lain
+func usernameWriteError(msg string, err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return apperror.New(apperror.CodeUsernameConflict, "username sudah digunakan", err)
+	}
+	return apperror.New(apperror.CodeDBError, msg, err)
+}
+
+// ChangeEmail dipakai setelah alamat baru terverifikasi (atau saat alamat lama dipulihkan),
+// sehingga is_verified ikut diset true. Alamat yang sudah ada di email_history tidak dicatat ulang.
+func (r *userRepository) ChangeEmail(ctx context.Context, userID, email string) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET email = ?, is_verified = TRUE WHERE id = ?`, email, userID)
+		if err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return apperror.New(apperror.CodeEmailConflict, "email sudah digunakan", err)
+			}
+			return apperror.New(apperror.CodeDBError, "query update users email gagal", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT IGNORE INTO email_history(email, user_id) VALUES(?, ?)`, email, userID)
+		if err != nil {
+			return apperror.New(apperror.CodeDBError, "query insert email_history gagal", err)
+		}
+
+		return nil
+	})
+}
+
+// Update menyimpan perubahan dari admin dalam satu transaksi. Riwayat username/email dan
+// user_roles hanya ditulis ulang jika nilainya memang berubah dibanding before.
+func (r *userRepository) Update(ctx context.Context, before, after *model.UserModel) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		// token_version nil berarti tidak diganti, supaya versi dari logout atau ganti password yang terjadi
+		// bersamaan tidak tertimpa nilai lama
+		_, err := tx.ExecContext(ctx, `UPDATE users SET username = ?, email = ?, is_verified = ?, token_version = COALESCE(?, token_version) WHERE id = ?`,
+			after.Username, after.Email, after.IsVerified, after.TokenVersion, after.ID)
+		if err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return apperror.New(apperror.CodeUserConflict, "username atau email sudah digunakan", err)
+			}
+			return apperror.New(apperror.CodeDBError, "query update users gagal", err)
+		}
+
+		if after.Username != nil && (before.Username == nil || *before.Username != *after.Username) {
+			if before.Username != nil {
+				_, err := tx.ExecContext(ctx, `UPDATE username_history SET used_until = NOW(), user_id = COALESCE(user_id, ?) 
+					WHERE username = ? AND used_until IS NULL`, after.ID, *before.Username)
+				if err != nil {
+					return apperror.New(apperror.CodeDBError, "query update username_history gagal", err)
+				}
+			}
+
+			_, err := tx.ExecContext(ctx, `INSERT INTO username_history(username, user_id, used_from) VALUES(?, ?, NOW())`,
+				*after.Username, after.ID)
+			if err != nil {
+				return usernameWriteError("query insert username_history gagal", err)
+			}
+		}
+
+		if before.Email != after.Email {
+			_, err := tx.ExecContext(ctx, `INSERT IGNORE INTO email_history(email, user_id) VALUES(?, ?)`, after.Email, after.ID)
+			if err != nil {
+				return apperror.New(apperror.CodeDBError, "query insert email_history gagal", err)
+			}
+		}
+
+		if !sameRoles(before.Roles, after.Roles) {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = ?`, after.ID); err != nil {
+				return apperror.New(apperror.CodeDBError, "query delete user_roles gagal", err)
+			}
+
+			for _, role := range after.Roles {
+				if _, err := tx.ExecContext(ctx, `INSERT INTO user_roles(user_id, role_id) VALUES(?, ?)`, after.ID, role.ID); err != nil {
+					return apperror.New(apperror.CodeDBError,
+						fmt.Sprintf("query insert user_roles gagal untuk role_id: %s", role.ID), err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete menghapus user beserta data turunannya lewat ON DELETE CASCADE. Riwayat username
+// dan email tidak ikut terhapus sehingga tetap tidak bisa dipakai ulang.
+func (r *userRepository) Delete(ctx context.Context, userID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query delete users gagal", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", nil)
+	}
+	return nil
+}
+
+func sameRoles(a, b []model.RoleModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	ids := make(map[string]struct{}, len(a))
+	for _, role := range a {
+		ids[role.ID] = struct{}{}
+	}
+	for _, role := range b {
+		if _, ok := ids[role.ID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateStatus memindahkan akun ke status lain. deleted_at hanya terisi untuk status deleted,
+// tokenVersion nil berarti token yang ada tetap berlaku.
+func (r *userRepository) UpdateStatus(ctx context.Context, userID, status string, reason *string, until *time.Time, tokenVersion *string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET status = ?, status_reason = ?, suspended_until = ?, 
+		deleted_at = IF(? = 'deleted', NOW(), NULL), token_version = COALESCE(?, token_version) WHERE id = ?`,
+		status, reason, until, status, tokenVersion, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update users status gagal", err)
+	}
+	return nil
+}
+
+func (r *userRepository) FindDeletedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM users WHERE status = 'deleted' AND deleted_at < ?`, before)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query select users deleted gagal", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "gagal scan users", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return ids, nil
+}
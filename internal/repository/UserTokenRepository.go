@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/model"
+	"time"
+)
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, t *model.UserTokenModel) error
+	FindByToken(ctx context.Context, purpose, token string) (*model.UserTokenModel, error)
+	MarkAsUsed(ctx context.Context, id string, now time.Time) error
+	RevokeByUser(ctx context.Context, userID, purpose string) error
+}
+
+type userTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, t *model.UserTokenModel) error {
+	query := `INSERT INTO user_tokens (id, user_id, purpose, token, payload, expires_at) VALUES(?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, t.ID, t.UserID, t.Purpose, t.Token, t.Payload, t.ExpiresAt)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query insert user_tokens gagal", err)
+	}
+	return nil
+}
+
+func (r *userTokenRepository) FindByToken(ctx context.Context, purpose, token string) (*model.UserTokenModel, error) {
+	query := `SELECT id, user_id, purpose, token, payload, expires_at, used_at, created_at 
+		FROM user_tokens WHERE purpose = ? AND token = ? LIMIT 1`
+
+	var t model.UserTokenModel
+	var payload sql.NullString
+	var usedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, purpose, token).
+		Scan(&t.ID, &t.UserID, &t.Purpose, &t.Token, &payload, &t.ExpiresAt, &usedAt, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[TOKEN_NOT_FOUND]", "token tidak ditemukan", err, 404)
+		}
+		return nil, apperror.New(apperror.CodeDBError, "query select user_tokens gagal", err)
+	}
+
+	if payload.Valid {
+		t.Payload = &payload.String
+	}
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+
+	return &t, nil
+}
+
+// MarkAsUsed menandai token terpakai hanya jika belum dipakai dan belum kadaluarsa, sehingga
+// dari beberapa request paralel dengan token yang sama hanya satu yang berhasil
+func (r *userTokenRepository) MarkAsUsed(ctx context.Context, id string, now time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE user_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND expires_at > ?`,
+		now, id, now)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update user_tokens used_at gagal", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperror.New("[TOKEN_USED]", "token sudah digunakan", nil, 400)
+	}
+	return nil
+}
+
+// RevokeByUser menghanguskan semua token user untuk purpose tertentu yang belum dipakai
+func (r *userTokenRepository) RevokeByUser(ctx context.Context, userID, purpose string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_tokens SET used_at = NOW() WHERE user_id = ? AND purpose = ? AND used_at IS NULL`, userID, purpose)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query revoke user_tokens gagal", err)
+	}
+	return nil
+}
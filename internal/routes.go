@@ -12,36 +12,118 @@ func RouteRegister(r *gin.Engine, app *bootstrap.Service) {
 	v := valigo.NewValigo()
 
 	authHandler := handler.NewAuthHandler(app.AuthService, v)
-	emailHandler := handler.NewEmailVerificationHandler(app.EmailVerificationService)
+	emailHandler := handler.NewEmailVerificationHandler(app.EmailVerificationService, v)
 	googleHandler := handler.NewGoogleAuthHandler(app.GoogleAuthService)
 	userHandler := handler.NewUserHandler(app.UserService, v)
+	phoneHandler := handler.NewPhoneHandler(app.PhoneService, v)
+	passwordHandler := handler.NewPasswordHandler(app.PasswordService, v)
+	canaryHandler := handler.NewCanaryHandler(app.CanaryService, v)
+	deviceHandler := handler.NewDeviceHandler(app.DeviceService)
+	profileHandler := handler.NewProfileHandler(app.ProfileService, v, app.AvatarMaxSize)
+	emailChangeHandler := handler.NewEmailChangeHandler(app.EmailChangeService, v)
+
+	if app.AssetsDir != "" {
+		r.Static("/assets", app.AssetsDir)
+	}
 
 	r.Use(app.Middleware.CORSMiddleware())
+	r.Use(app.Middleware.ClientInfoMiddleware())
 	api := r.Group("/api")
 
+	// rate limit per route
+	loginLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitLogin)
+	phoneLoginLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitPhoneLogin)
+	registerLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitRegister)
+	emailLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitEmailVerification)
+	googleLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitGoogleCallback)
+	forgotLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitPasswordForgot)
+	resetLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitPasswordReset)
+	linkLimit := app.Middleware.RateLimitMiddleware(middleware.RateLimitAccountLink)
+
+	// captcha: register selalu, login setelah beberapa kali gagal dari IP yang sama
+	registerCaptcha := app.Middleware.CaptchaMiddleware(middleware.CaptchaAlways)
+	loginCaptcha := app.Middleware.CaptchaMiddleware(middleware.CaptchaAfterFailures)
+
 	// auth
-	api.POST("/register", authHandler.Register)
-	api.POST("/login", authHandler.Login)
+	api.POST("/register", registerLimit, registerCaptcha, authHandler.Register)
+	api.POST("/login", loginLimit, loginCaptcha, authHandler.Login)
+	api.POST("/login/phone", phoneLoginLimit, authHandler.RequestPhoneLogin)
+	api.POST("/login/phone/verify", phoneLoginLimit, loginCaptcha, authHandler.LoginWithPhone)
+	api.POST("/account/unlock", linkLimit, authHandler.UnlockAccount)
+	api.POST("/account/revoke", linkLimit, authHandler.RevokeSessions)
+	api.POST("/login/confirm", linkLimit, authHandler.ConfirmLogin)
+
+	// password
+	api.POST("/password/forgot", forgotLimit, passwordHandler.ForgotPassword)
+	api.POST("/password/reset", resetLimit, passwordHandler.ResetPassword)
 
 	// email
-	api.GET("/email-verification", emailHandler.VerifyEmail)
+	api.GET("/email-verification", emailLimit, emailHandler.VerifyEmail)
+	api.POST("/email/change/confirm", emailLimit, emailChangeHandler.ConfirmChange)
+	api.POST("/email/change/revert", linkLimit, emailChangeHandler.RevertChange)
 
 	// google OAuth2
 	api.GET("/google/login", googleHandler.GoogleLogin)
-	api.GET("/google/callback", googleHandler.GoogleCallback)
+	api.GET("/google/callback", googleLimit, googleHandler.GoogleCallback)
 
 	auth := api.Group("")
 	auth.Use(app.Middleware.AuthMiddleware())
 
-	eVerify := auth.Group("")
+	// ganti password, boleh diakses token terbatas
+	auth.POST("/password/change", passwordHandler.ChangePassword)
+
+	full := auth.Group("")
+	full.Use(app.Middleware.FullAccessMiddleware())
+
+	// verifikasi email dengan kode OTP
+	full.POST("/email-verification/code", emailLimit, emailHandler.VerifyCode)
+	full.POST("/email-verification/resend", emailLimit, emailHandler.ResendVerification)
+
+	// akun sendiri, belum perlu verifikasi email
+	full.GET("/me", userHandler.GetMe)
+	full.PATCH("/me", userHandler.UpdateMe)
+	full.PATCH("/me/username", userHandler.ChangeUsername)
+	full.POST("/me/email", emailLimit, emailChangeHandler.RequestChange)
+	full.POST("/me/avatar", profileHandler.UploadMyAvatar)
+	full.DELETE("/me/avatar", profileHandler.DeleteMyAvatar)
+
+	// perangkat yang dikenal
+	full.GET("/devices", deviceHandler.ListDevices)
+	full.DELETE("/devices/:id", deviceHandler.ForgetDevice)
+
+	eVerify := full.Group("")
 	eVerify.Use(app.Middleware.EmailVerifiedMiddleware())
 
 	// role super admin dan admin
 	superAndAdmin := app.Middleware.RoleMiddleware(middleware.MatchAny, "super admin", "admin")
+	// user target harus terlihat oleh role pengakses, lihat USER_HIDDEN_ROLES
+	userVisible := app.Middleware.UserVisibleMiddleware()
+
+	// nomor telepon
+	eVerify.POST("/phone", phoneHandler.RequestVerification)
+	eVerify.POST("/phone/verify", phoneHandler.Verify)
 
 	// users
 	eVerify.GET("/users", superAndAdmin, userHandler.GetAllUsers)
 	eVerify.POST("/users/create", superAndAdmin, userHandler.CreateUser)
+	eVerify.GET("/users/username-history", superAndAdmin, userHandler.UsernameHistory)
+	eVerify.GET("/users/:id", superAndAdmin, userVisible, userHandler.GetUser)
+	eVerify.PATCH("/users/:id", superAndAdmin, userVisible, userHandler.UpdateUser)
+	eVerify.DELETE("/users/:id", superAndAdmin, userVisible, userHandler.DeleteUser)
+	eVerify.PATCH("/users/:id/status", superAndAdmin, userVisible, userHandler.ChangeUserStatus)
+	eVerify.POST("/users/:id/reset-password", superAndAdmin, userVisible, passwordHandler.AdminResetPassword)
+	eVerify.POST("/users/:id/unlock", superAndAdmin, userVisible, userHandler.UnlockUser)
+	eVerify.GET("/users/:id/profile", superAndAdmin, userVisible, profileHandler.GetProfile)
+	eVerify.POST("/users/:id/profile", superAndAdmin, userVisible, profileHandler.CreateProfile)
+	eVerify.PATCH("/users/:id/profile", superAndAdmin, userVisible, profileHandler.UpdateProfile)
+	eVerify.DELETE("/users/:id/avatar", superAndAdmin, userVisible, profileHandler.DeleteAvatar)
+
+	// canary (honeytoken)
+	eVerify.POST("/canary/accounts", superAndAdmin, canaryHandler.CreateAccount)
+	eVerify.GET("/canary/accounts", superAndAdmin, canaryHandler.ListAccounts)
+	eVerify.POST("/canary/credentials", superAndAdmin, canaryHandler.CreateCredential)
+	eVerify.GET("/canary/credentials", superAndAdmin, canaryHandler.ListCredentials)
+	eVerify.DELETE("/canary/credentials/:id", superAndAdmin, canaryHandler.DeleteCredential)
 
 	// logout
 	eVerify.POST("/logout", authHandler.Logout)
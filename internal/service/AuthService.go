@@ -3,51 +3,101 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/config"
 	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/dto/response"
 	"github.com/gogaruda/auth/internal/model"
 	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/notify"
+	"github.com/gogaruda/auth/pkg/password"
 	"github.com/gogaruda/auth/pkg/utils"
+	"html"
+	"log"
+	"strings"
+	"sync"
+	"time"
 )
 
 type AuthService interface {
 	Register(ctx context.Context, req request.RegisterRequest) error
-	Login(ctx context.Context, req request.LoginRequest) (string, error)
+	Login(ctx context.Context, req request.LoginRequest) (*response.LoginResponse, error)
+	RequestPhoneLogin(ctx context.Context, req request.PhoneRequest) error
+	LoginWithPhone(ctx context.Context, req request.PhoneLoginRequest) (*response.LoginResponse, error)
+	UnlockAccount(ctx context.Context, token string) error
+	RevokeSessions(ctx context.Context, token string) error
+	ConfirmLogin(ctx context.Context, token string) (*response.LoginResponse, error)
+	// IssueToken menjalankan cek status, perangkat dan kewajiban ganti password sebelum membuat JWT,
+	// dipakai juga oleh login Google
+	IssueToken(ctx context.Context, user *model.UserModel) (*response.LoginResponse, error)
 	Logout(userID string) error
 }
 
 type authService struct {
 	authRepo repository.AuthRepository
+	userRepo repository.UserRepository
 	roleRepo repository.RoleRepository
 	config   *config.AppConfig
 	ut       utils.Utils
 	email    EmailVerificationService
+	phone    PhoneService
+	policy   password.Policy
+	tokens   UserTokenService
+	mail     mailer.Mailer
+	canary   CanaryService
+	devices  DeviceService
+	notify   NotificationService
+
+	dummyOnce sync.Once
+	dummyHash string
 }
 
 func NewAuthService(
 	a repository.AuthRepository,
+	ur repository.UserRepository,
 	r repository.RoleRepository,
 	cfg *config.AppConfig,
 	u utils.Utils,
 	e EmailVerificationService,
+	p PhoneService,
+	pp password.Policy,
+	t UserTokenService,
+	m mailer.Mailer,
+	cs CanaryService,
+	d DeviceService,
+	n NotificationService,
 ) AuthService {
-	return &authService{authRepo: a, roleRepo: r, config: cfg, email: e, ut: u}
+	return &authService{
+		authRepo: a, userRepo: ur, roleRepo: r, config: cfg, email: e, ut: u,
+		phone: p, policy: pp, tokens: t, mail: m, canary: cs, devices: d, notify: n,
+	}
 }
 
 func (s *authService) Register(ctx context.Context, req request.RegisterRequest) error {
+	if err := s.policy.Validate(password.Input{Password: req.Password, Username: req.Username, Email: req.Email, CheckBreach: true}); err != nil {
+		return err
+	}
+
 	isUsernameExists, err := s.authRepo.IsUsernameExists(ctx, req.Username)
 	if err != nil {
 		return err
 	}
-	if isUsernameExists {
-		return apperror.New(apperror.CodeUsernameConflict, "username sudah terdaftar", errors.New("username sudah terdaftar"))
-	}
 
 	isEmailExists, err := s.authRepo.IsEmailExists(ctx, req.Email)
 	if err != nil {
 		return err
 	}
+
+	if s.config.Sec.Hardened && (isUsernameExists || isEmailExists) {
+		return s.reportRegisterConflict(ctx, req, isEmailExists)
+	}
+
+	if isUsernameExists {
+		return apperror.New(apperror.CodeUsernameConflict, "username sudah terdaftar", errors.New("username sudah terdaftar"))
+	}
+
 	if isEmailExists {
 		return apperror.New(apperror.CodeEmailConflict, "email sudah terdaftar", errors.New("email sudah terdaftar"))
 	}
@@ -57,7 +107,11 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 		return err
 	}
 
-	hashPass, _ := s.ut.GenerateHash(req.Password)
+	hashPass, err := s.ut.GenerateHash(req.Password)
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal generate password", err)
+	}
+
 	user := model.UserModel{
 		ID:             s.ut.GenerateULID(),
 		Username:       &req.Username,
@@ -70,10 +124,24 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 		Roles:          roles,
 	}
 
+	if req.Profile != nil {
+		user.Profile = newProfileModel(s.ut.GenerateULID(), user.ID, req.Profile)
+	}
+
 	if err := s.authRepo.Create(ctx, user); err != nil {
 		return err
 	}
 
+	if s.config.Sec.Hardened {
+		// Email dikirim di belakang agar waktu respon sama dengan jalur konflik
+		go func(ctx context.Context) {
+			if err := s.email.SendVerification(ctx, user); err != nil {
+				log.Printf("[WARN] gagal mengirim verifikasi email user %s: %v", user.ID, err)
+			}
+		}(context.WithoutCancel(ctx))
+		return nil
+	}
+
 	if err := s.email.SendVerification(ctx, user); err != nil {
 		return err
 	}
@@ -81,15 +149,416 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 	return nil
 }
 
-func (s *authService) Login(ctx context.Context, req request.LoginRequest) (string, error) {
+// reportRegisterConflict dipakai pada mode hardened: konflik tidak dijawab di HTTP,
+// tetapi diberitahukan ke pemilik alamat email yang didaftarkan
+func (s *authService) reportRegisterConflict(ctx context.Context, req request.RegisterRequest, emailExists bool) error {
+	// Hash tetap dibuat supaya durasinya setara dengan registrasi yang berhasil
+	if _, err := s.ut.GenerateHash(req.Password); err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal generate password", err)
+	}
+
+	subject := "Percobaan Registrasi"
+	body := "<p>Seseorang mencoba mendaftar menggunakan alamat email ini, padahal email ini sudah terdaftar.</p>" +
+		"<p>Jika itu Anda, silahkan login atau gunakan fitur lupa password. Jika bukan, abaikan email ini.</p>"
+	if !emailExists {
+		subject = "Registrasi Gagal"
+		body = fmt.Sprintf("<p>Registrasi dengan username <b>%s</b> gagal karena username tersebut sudah dipakai.</p>"+
+			"<p>Silahkan daftar ulang dengan username lain.</p>", html.EscapeString(req.Username))
+	}
+
+	go func(ctx context.Context) {
+		if err := s.mail.Send(req.Email, subject, body); err != nil {
+			log.Printf("[WARN] gagal mengirim email konflik registrasi: %v", err)
+		}
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+func (s *authService) Login(ctx context.Context, req request.LoginRequest) (*response.LoginResponse, error) {
+	result, err := s.login(ctx, req)
+	if err != nil && s.config.Sec.Hardened && isLoginRejection(err) {
+		return nil, apperror.New(apperror.CodeInvalidCredential, "identifier atau password salah", err)
+	}
+
+	return result, err
+}
+
+func (s *authService) login(ctx context.Context, req request.LoginRequest) (*response.LoginResponse, error) {
+	// Identifier umpan tidak punya akun, dijawab seperti user yang tidak ada
+	if cred := s.canary.MatchIdentifier(ctx, req.Identifier); cred != nil {
+		s.canary.Trip(ctx, CanaryTrip{Source: CanarySourceLogin, Identifier: req.Identifier, Credential: cred})
+		s.dummyCompare(req.Password)
+		return nil, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", nil)
+	}
+
 	user, err := s.authRepo.Identifier(ctx, req.Identifier)
-	if err != nil || !s.ut.CompareHash(*user.Password, req.Password) {
-		return "", err
+	if err != nil {
+		if apperror.Is(err, apperror.CodeUserNotFound) {
+			s.dummyCompare(req.Password)
+		}
+		return nil, err
+	}
+
+	// Akun canary tidak pernah bisa login, password benar pun dijawab salah
+	if user.IsCanary {
+		s.canary.Trip(ctx, CanaryTrip{Source: CanarySourceLogin, UserID: user.ID, Identifier: req.Identifier})
+		if user.Password != nil {
+			s.ut.CompareHash(*user.Password, req.Password)
+		}
+		return nil, apperror.New(apperror.CodeInvalidCredential, "password salah", errors.New("akun canary"))
+	}
+
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		s.dummyCompare(req.Password)
+		return nil, errAccountLocked()
+	}
+
+	if wait := s.loginDelay(user, now); wait > 0 {
+		s.dummyCompare(req.Password)
+		return nil, apperror.New(codeLoginDelayed,
+			fmt.Sprintf("terlalu banyak percobaan login, coba lagi dalam %d detik", int(wait.Seconds()+0.5)), nil, 429)
+	}
+
+	if user.Password == nil || !s.ut.CompareHash(*user.Password, req.Password) {
+		return nil, s.recordLoginFailure(ctx, user, now)
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.authRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.rehashPassword(ctx, user.ID, *user.Password, req.Password)
+
+	return s.IssueToken(ctx, user)
+}
+
+func (s *authService) RequestPhoneLogin(ctx context.Context, req request.PhoneRequest) error {
+	phone, err := s.phone.Normalize(req.Phone)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByPhone(ctx, phone)
+	if err != nil {
+		// Nomor yang tidak terdaftar dijawab sama seperti yang terdaftar
+		if apperror.Is(err, apperror.CodeUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.IsCanary {
+		s.canary.Trip(ctx, CanaryTrip{Source: CanarySourcePhoneLogin, UserID: user.ID, Identifier: phone})
+		return nil
+	}
+
+	return s.phone.SendOTP(ctx, user.ID, phone, model.PhonePurposeLogin)
+}
+
+func (s *authService) LoginWithPhone(ctx context.Context, req request.PhoneLoginRequest) (*response.LoginResponse, error) {
+	phone, err := s.phone.Normalize(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByPhone(ctx, phone)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeUserNotFound) {
+			return nil, apperror.New(apperror.CodeInvalidCredential, "nomor telepon atau kode salah", err)
+		}
+		return nil, err
+	}
+
+	if user.IsCanary {
+		s.canary.Trip(ctx, CanaryTrip{Source: CanarySourcePhoneLogin, UserID: user.ID, Identifier: phone})
+		return nil, apperror.New(apperror.CodeInvalidCredential, "nomor telepon atau kode salah", errors.New("akun canary"))
+	}
+
+	if _, err := s.phone.VerifyOTP(ctx, user.ID, model.PhonePurposeLogin, req.Code); err != nil {
+		if s.config.Sec.Hardened && !apperror.Is(err, apperror.CodeDBError) {
+			return nil, apperror.New(apperror.CodeInvalidCredential, "nomor telepon atau kode salah", err)
+		}
+		return nil, err
+	}
+
+	return s.IssueToken(ctx, user)
+}
+
+// loginDelay menghitung jeda bertingkat: BaseDelay * 2^(gagal - DelayAfter), maksimal MaxDelay
+func (s *authService) loginDelay(user *model.UserModel, now time.Time) time.Duration {
+	cfg := s.config.Lock
+	if user.LastFailedLoginAt == nil || user.FailedLoginAttempts < cfg.DelayAfter || cfg.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := cfg.BaseDelay
+	for i := cfg.DelayAfter; i < user.FailedLoginAttempts && delay < cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, cfg.MaxDelay)
+
+	return time.Until(user.LastFailedLoginAt.Add(delay))
+}
+
+// recordLoginFailure mencatat gagal login dan mengunci akun jika melewati batas,
+// link untuk membuka kunci dikirim ke email user
+func (s *authService) recordLoginFailure(ctx context.Context, user *model.UserModel, now time.Time) error {
+	invalid := apperror.New(apperror.CodeInvalidCredential, "password salah", errors.New("password tidak cocok"))
+
+	attempts, lockedUntil, err := s.authRepo.RecordLoginFailure(ctx, user.ID, now, s.config.Lock.Threshold, s.config.Lock.Duration)
+	if err != nil {
+		return err
+	}
+
+	if lockedUntil == nil {
+		return invalid
+	}
+
+	// hanya percobaan yang memasang kunci yang mengirim email, percobaan paralel sesudahnya tidak
+	if attempts != s.config.Lock.Threshold {
+		return errAccountLocked()
+	}
+
+	if err := s.sendUnlockEmail(ctx, user); err != nil {
+		log.Printf("[WARN] gagal mengirim email buka kunci user %s: %v", user.ID, err)
+	}
+
+	return errAccountLocked()
+}
+
+func (s *authService) sendUnlockEmail(ctx context.Context, user *model.UserModel) error {
+	if err := s.tokens.RevokeAll(ctx, user.ID, model.TokenPurposeAccountUnlock); err != nil {
+		return err
+	}
+
+	tok, err := s.tokens.Issue(ctx, user.ID, model.TokenPurposeAccountUnlock, nil, s.config.Lock.Duration)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s?token=%s", s.config.Mail.FrontUnlockUrl, tok)
+	body := fmt.Sprintf("<p>Akun Anda dikunci sementara karena terlalu banyak percobaan login yang gagal.</p>"+
+		"<p>Jika itu Anda, klik disini untuk membuka kunci: <a href='%s'>%s</a></p>"+
+		"<p>Jika bukan, segera ganti password Anda setelah akun terbuka.</p>", url, url)
+
+	return s.mail.Send(user.Email, "Akun Terkunci", body)
+}
+
+func (s *authService) UnlockAccount(ctx context.Context, token string) error {
+	t, err := s.tokens.Consume(ctx, model.TokenPurposeAccountUnlock, token)
+	if err != nil {
+		return err
+	}
+
+	return s.authRepo.ResetLoginFailures(ctx, t.UserID)
+}
+
+const (
+	codeAccountLocked      = "[ACCOUNT_LOCKED]"
+	codeLoginDelayed       = "[LOGIN_DELAYED]"
+	codeAccountSuspended   = "[ACCOUNT_SUSPENDED]"
+	codeAccountDeactivated = "[ACCOUNT_DEACTIVATED]"
+	codeAccountDeleted     = "[ACCOUNT_DELETED]"
+)
+
+// IsLoginFailure menandakan error login akibat kredensial atau kode yang salah (termasuk akun
+// terkunci karenanya), bukan akibat status akun atau gangguan server
+func IsLoginFailure(err error) bool {
+	for _, code := range []string{
+		apperror.CodeInvalidCredential,
+		apperror.CodeUserNotFound,
+		codeAccountLocked,
+		codeLoginDelayed,
+		"[CODE_INVALID]",
+		"[CODE_INVALIDATED]",
+		"[CODE_LOCKED]",
+	} {
+		if apperror.Is(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfirmLogin melepas JWT untuk login yang ditahan karena perangkat baru
+func (s *authService) ConfirmLogin(ctx context.Context, token string) (*response.LoginResponse, error) {
+	userID, err := s.devices.Confirm(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.authRepo.LoginByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, errAccountLocked()
+	}
+
+	if err := ensureActive(ctx, s.userRepo, user); err != nil {
+		return nil, err
+	}
+
+	return s.releaseToken(user)
+}
+
+// RevokeSessions dipanggil dari link "bukan saya" pada email notifikasi,
+// semua token login user langsung tidak berlaku
+func (s *authService) RevokeSessions(ctx context.Context, token string) error {
+	t, err := s.tokens.Consume(ctx, model.TokenPurposeSessionRevoke, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokens.RevokeAll(ctx, t.UserID, model.TokenPurposeSessionRevoke); err != nil {
+		return err
+	}
+
+	return s.authRepo.UpdateTokenVersion(t.UserID, s.ut.GenerateULID())
+}
+
+// trackLogin mencatat perangkat login dan memberi tahu user jika perangkatnya baru.
+// Dipakai bersama oleh login password, telepon dan Google
+func trackLogin(ctx context.Context, devices DeviceService, n NotificationService, user *model.UserModel) {
+	device, isNew, err := devices.Touch(ctx, user.ID)
+	if err != nil {
+		log.Printf("[WARN] gagal mencatat perangkat user %s: %v", user.ID, err)
+		return
+	}
+
+	if isNew {
+		n.Notify(ctx, user, notify.EventNewLogin, map[string]string{
+			"Alamat IP": device.IP,
+			"Perangkat": device.UserAgent,
+		})
+	}
+}
+
+// CheckAccountStatus menolak akun yang tidak aktif, dipakai saat login dan oleh AuthMiddleware
+func CheckAccountStatus(user *model.UserModel, now time.Time) error {
+	switch user.EffectiveStatus(now) {
+	case model.UserStatusSuspended:
+		msg := "akun sedang ditangguhkan"
+		if user.SuspendedUntil != nil {
+			msg += " sampai " + user.SuspendedUntil.Format("02-01-2006 15:04")
+		}
+		if user.StatusReason != nil {
+			msg += ", alasan: " + *user.StatusReason
+		}
+		return apperror.New(codeAccountSuspended, msg, nil, 403)
+	case model.UserStatusDeactivated:
+		return apperror.New(codeAccountDeactivated, "akun sudah dinonaktifkan", nil, 403)
+	case model.UserStatusDeleted:
+		return apperror.New(codeAccountDeleted, "akun sudah dihapus", nil, 410)
+	}
+	return nil
+}
+
+// ensureActive memeriksa status akun sebelum token dibuat. Suspend yang sudah lewat
+// tanggal berakhirnya baru dicabut di sini, tidak ada job terjadwal.
+func ensureActive(ctx context.Context, repo repository.UserRepository, user *model.UserModel) error {
+	if err := CheckAccountStatus(user, time.Now()); err != nil {
+		return err
+	}
+
+	if user.Status == model.UserStatusSuspended {
+		if err := repo.UpdateStatus(ctx, user.ID, model.UserStatusActive, nil, nil, nil); err != nil {
+			return err
+		}
+		user.Status, user.StatusReason, user.SuspendedUntil = model.UserStatusActive, nil, nil
+	}
+	return nil
+}
+
+func errAccountLocked() error {
+	return apperror.New(codeAccountLocked, "akun terkunci sementara karena terlalu banyak percobaan login gagal", nil, 423)
+}
+
+// isLoginRejection menandai error yang bisa membedakan akun ada atau tidak
+func isLoginRejection(err error) bool {
+	for _, code := range []string{apperror.CodeUserNotFound, apperror.CodeInvalidCredential, codeAccountLocked, codeLoginDelayed} {
+		if apperror.Is(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// dummyCompare menjalankan perbandingan hash palsu pada mode hardened supaya
+// waktu respon untuk akun yang tidak ada sama dengan password yang salah
+func (s *authService) dummyCompare(password string) {
+	if !s.config.Sec.Hardened {
+		return
+	}
+
+	s.dummyOnce.Do(func() {
+		hash, err := s.ut.GenerateHash(s.ut.GenerateULID())
+		if err != nil {
+			log.Printf("[WARN] gagal membuat dummy hash: %v", err)
+			return
+		}
+		s.dummyHash = hash
+	})
+
+	if s.dummyHash != "" {
+		s.ut.CompareHash(s.dummyHash, password)
+	}
+}
+
+// rehashPassword meng-upgrade hash lama secara bertahap, kegagalan tidak membatalkan login
+func (s *authService) rehashPassword(ctx context.Context, userID, hash, password string) {
+	if !s.ut.NeedsRehash(hash) {
+		return
 	}
 
+	newHash, err := s.ut.GenerateHash(password)
+	if err != nil {
+		log.Printf("[WARN] gagal rehash password user %s: %v", userID, err)
+		return
+	}
+
+	if err := s.authRepo.UpdatePassword(ctx, userID, newHash); err != nil {
+		log.Printf("[WARN] gagal menyimpan rehash password user %s: %v", userID, err)
+	}
+}
+
+// IssueToken membuat JWT baru dan menghanguskan token lama.
+// User yang wajib ganti password hanya mendapat token terbatas.
+func (s *authService) IssueToken(ctx context.Context, user *model.UserModel) (*response.LoginResponse, error) {
+	if err := ensureActive(ctx, s.userRepo, user); err != nil {
+		return nil, err
+	}
+
+	held, err := s.devices.Hold(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return &response.LoginResponse{PendingConfirmation: true}, nil
+	}
+
+	trackLogin(ctx, s.devices, s.notify, user)
+	return s.releaseToken(user)
+}
+
+// releaseToken membuat JWT tanpa pemeriksaan perangkat, dipanggil setelah login lolos semua cek
+func (s *authService) releaseToken(user *model.UserModel) (*response.LoginResponse, error) {
 	newVersion := s.ut.GenerateULID()
 	if err := s.authRepo.UpdateTokenVersion(user.ID, newVersion); err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if user.MustChangePassword || s.passwordExpired(user) {
+		token, err := s.ut.GenerateRestrictedJWT(user.ID, newVersion, utils.ScopePasswordChange, s.config)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInternalError, "gagal buat JWT", err)
+		}
+		return &response.LoginResponse{Token: token, MustChangePassword: true}, nil
 	}
 
 	var roles []string
@@ -99,10 +568,27 @@ func (s *authService) Login(ctx context.Context, req request.LoginRequest) (stri
 
 	token, err := s.ut.GenerateJWT(user.ID, newVersion, user.IsVerified, roles, s.config)
 	if err != nil {
-		return "", apperror.New(apperror.CodeInternalError, "gagal buat JWT", err)
+		return nil, apperror.New(apperror.CodeInternalError, "gagal buat JWT", err)
+	}
+
+	return &response.LoginResponse{Token: token}, nil
+}
+
+// passwordExpired memakai masa berlaku terpendek dari role yang dimiliki user
+func (s *authService) passwordExpired(user *model.UserModel) bool {
+	if user.PasswordChangedAt == nil {
+		return false
+	}
+
+	var maxAge time.Duration
+	for _, r := range user.Roles {
+		age, ok := s.config.Pass.ExpiryByRole[strings.ToLower(r.Name)]
+		if ok && (maxAge == 0 || age < maxAge) {
+			maxAge = age
+		}
 	}
 
-	return token, nil
+	return maxAge > 0 && time.Since(*user.PasswordChangedAt) > maxAge
 }
 
 func (s *authService) Logout(userID string) error {
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/dto/response"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/alert"
+	"github.com/gogaruda/auth/pkg/clientinfo"
+	"github.com/gogaruda/auth/pkg/utils"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	CanarySourceLogin      = "login"
+	CanarySourcePhoneLogin = "phone_login"
+	CanarySourceGoogle     = "google"
+	CanarySourceToken      = "token"
+)
+
+// CanaryTrip adalah detail pemakaian akun atau kredensial canary yang terdeteksi
+type CanaryTrip struct {
+	Source     string
+	UserID     string
+	Identifier string
+	Credential *model.CanaryCredentialModel
+}
+
+// CanaryService mengelola akun dan kredensial umpan. Akun atau kredensial ini
+// tidak pernah dipakai secara sah, jadi setiap pemakaiannya dianggap kebocoran
+type CanaryService interface {
+	CreateAccount(ctx context.Context, req *request.UserCreateRequest) error
+	ListAccounts(ctx context.Context) ([]response.CanaryAccountResponse, error)
+	CreateCredential(ctx context.Context, adminID string, req *request.CanaryCredentialRequest) (*response.CanaryCredentialResponse, error)
+	ListCredentials(ctx context.Context) ([]response.CanaryCredentialResponse, error)
+	DeleteCredential(ctx context.Context, id string) error
+	MatchIdentifier(ctx context.Context, identifier string) *model.CanaryCredentialModel
+	MatchToken(ctx context.Context, token string) *model.CanaryCredentialModel
+	Trip(ctx context.Context, t CanaryTrip)
+}
+
+type canaryService struct {
+	repo     repository.CanaryRepository
+	userRepo repository.UserRepository
+	authRepo repository.AuthRepository
+	roleRepo repository.RoleRepository
+	alert    alert.Alerter
+	ut       utils.Utils
+	cfg      *config.AppConfig
+}
+
+func NewCanaryService(
+	r repository.CanaryRepository,
+	ur repository.UserRepository,
+	ar repository.AuthRepository,
+	rr repository.RoleRepository,
+	a alert.Alerter,
+	u utils.Utils,
+	c *config.AppConfig,
+) CanaryService {
+	return &canaryService{repo: r, userRepo: ur, authRepo: ar, roleRepo: rr, alert: a, ut: u, cfg: c}
+}
+
+// CreateAccount membuat akun umpan yang tampak seperti akun biasa. Password sengaja
+// tidak melewati policy karena akun ini memang dibuat untuk dicoba penyerang
+func (s *canaryService) CreateAccount(ctx context.Context, req *request.UserCreateRequest) error {
+	if err := s.ensureUnused(ctx, req.Username, req.Email); err != nil {
+		return err
+	}
+
+	roles, err := s.roleRepo.CheckRoles(ctx, req.Roles)
+	if err != nil {
+		return err
+	}
+
+	hashPass, err := s.ut.GenerateHash(req.Password)
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal generate password", err)
+	}
+
+	user := model.UserModel{
+		ID:         s.ut.GenerateULID(),
+		Username:   &req.Username,
+		Email:      req.Email,
+		Password:   &hashPass,
+		IsVerified: true,
+		IsCanary:   true,
+		Roles:      roles,
+	}
+
+	if req.Profile != nil {
+		user.Profile = newProfileModel(s.ut.GenerateULID(), user.ID, req.Profile)
+	}
+
+	return s.userRepo.Create(ctx, user)
+}
+
+func (s *canaryService) ListAccounts(ctx context.Context) ([]response.CanaryAccountResponse, error) {
+	return s.repo.ListAccounts(ctx)
+}
+
+func (s *canaryService) CreateCredential(ctx context.Context, adminID string, req *request.CanaryCredentialRequest) (*response.CanaryCredentialResponse, error) {
+	c := &model.CanaryCredentialModel{
+		ID:        s.ut.GenerateULID(),
+		Kind:      req.Kind,
+		Label:     req.Label,
+		CreatedBy: &adminID,
+		CreatedAt: time.Now(),
+	}
+	res := &response.CanaryCredentialResponse{ID: c.ID, Kind: c.Kind, Label: c.Label, CreatedAt: c.CreatedAt}
+
+	switch req.Kind {
+	case model.CanaryKindIdentifier:
+		identifier := normalizeIdentifier(req.Identifier)
+		if err := s.ensureUnused(ctx, identifier, identifier); err != nil {
+			return nil, err
+		}
+		c.Value = identifier
+		res.Identifier = identifier
+
+	case model.CanaryKindToken:
+		// user_id acak yang tidak pernah ada, token hanya ditampilkan sekali
+		token, err := s.ut.GenerateCanaryJWT(s.ut.GenerateULID(), []string{"super admin"}, s.cfg.Sec.CanaryTokenTTL, s.cfg)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInternalError, "gagal membuat token canary", err)
+		}
+		c.Value = s.ut.HashToken(token)
+		res.Token = token
+
+	default:
+		return nil, apperror.New(apperror.CodeBadRequest, "jenis kredensial canary tidak dikenal", nil)
+	}
+
+	if _, err := s.repo.FindCredential(ctx, c.Kind, c.Value); err == nil {
+		return nil, apperror.New(apperror.CodeResourceConflict, "kredensial canary sudah ada", nil)
+	} else if !apperror.Is(err, apperror.CodeResourceNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.CreateCredential(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *canaryService) ListCredentials(ctx context.Context) ([]response.CanaryCredentialResponse, error) {
+	list, err := s.repo.ListCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.CanaryCredentialResponse, 0, len(list))
+	for _, c := range list {
+		item := response.CanaryCredentialResponse{
+			ID:              c.ID,
+			Kind:            c.Kind,
+			Label:           c.Label,
+			LastTriggeredAt: c.LastTriggeredAt,
+			CreatedAt:       c.CreatedAt,
+		}
+		// hash token tidak ditampilkan
+		if c.Kind == model.CanaryKindIdentifier {
+			item.Identifier = c.Value
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func (s *canaryService) DeleteCredential(ctx context.Context, id string) error {
+	return s.repo.DeleteCredential(ctx, id)
+}
+
+func (s *canaryService) MatchIdentifier(ctx context.Context, identifier string) *model.CanaryCredentialModel {
+	return s.match(ctx, model.CanaryKindIdentifier, normalizeIdentifier(identifier))
+}
+
+func (s *canaryService) MatchToken(ctx context.Context, token string) *model.CanaryCredentialModel {
+	return s.match(ctx, model.CanaryKindToken, s.ut.HashToken(token))
+}
+
+// match tidak mengembalikan error, gangguan database tidak boleh menghalangi login
+func (s *canaryService) match(ctx context.Context, kind, value string) *model.CanaryCredentialModel {
+	if value == "" {
+		return nil
+	}
+
+	c, err := s.repo.FindCredential(ctx, kind, value)
+	if err != nil {
+		if !apperror.Is(err, apperror.CodeResourceNotFound) {
+			log.Printf("[WARN] gagal memeriksa kredensial canary: %v", err)
+		}
+		return nil
+	}
+	return c
+}
+
+// Trip mencatat dan mengirim alert prioritas tinggi. Pengiriman berjalan di belakang
+// agar respon ke penyerang tidak berbeda dari kegagalan login biasa
+func (s *canaryService) Trip(ctx context.Context, t CanaryTrip) {
+	info := clientinfo.FromContext(ctx)
+	fields := info.Fields()
+	fields["source"] = t.Source
+	if t.UserID != "" {
+		fields["user_id"] = t.UserID
+	}
+	if t.Identifier != "" {
+		fields["identifier"] = t.Identifier
+	}
+
+	message := "akun canary dipakai"
+	if t.Credential != nil {
+		message = "kredensial canary dipakai: " + t.Credential.Label
+		fields["credential_id"] = t.Credential.ID
+		fields["credential_kind"] = t.Credential.Kind
+		fields["credential_label"] = t.Credential.Label
+
+		if err := s.repo.MarkTriggered(ctx, t.Credential.ID); err != nil {
+			log.Printf("[WARN] gagal menandai kredensial canary %s: %v", t.Credential.ID, err)
+		}
+	}
+
+	event := alert.Event{
+		Type:     "canary_triggered",
+		Severity: alert.SeverityHigh,
+		Message:  message,
+		Time:     time.Now(),
+		Fields:   fields,
+	}
+
+	go func(ctx context.Context) {
+		if err := s.alert.Send(ctx, event); err != nil {
+			log.Printf("[WARN] gagal mengirim alert canary: %v", err)
+		}
+	}(context.WithoutCancel(ctx))
+}
+
+func (s *canaryService) ensureUnused(ctx context.Context, username, email string) error {
+	usernameExists, err := s.authRepo.IsUsernameExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if usernameExists {
+		return apperror.New(apperror.CodeUsernameConflict, "username sudah digunakan", errors.New("username sudah digunakan"))
+	}
+
+	emailExists, err := s.authRepo.IsEmailExists(ctx, email)
+	if err != nil {
+		return err
+	}
+	if emailExists {
+		return apperror.New(apperror.CodeEmailConflict, "email sudah digunakan", errors.New("email sudah digunakan"))
+	}
+
+	return nil
+}
+
+func normalizeIdentifier(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/dto/response"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/clientinfo"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/utils"
+	"html"
+	"strings"
+)
+
+// maxUserAgent mengikuti panjang kolom user_devices.user_agent
+const maxUserAgent = 255
+
+const codeDeviceNotFound = "[DEVICE_NOT_FOUND]"
+
+type DeviceService interface {
+	Touch(ctx context.Context, userID string) (*model.UserDeviceModel, bool, error)
+	Hold(ctx context.Context, user *model.UserModel) (bool, error)
+	Confirm(ctx context.Context, token string) (string, error)
+	List(ctx context.Context, userID string) ([]response.DeviceResponse, error)
+	Forget(ctx context.Context, userID, deviceID string) error
+}
+
+type deviceService struct {
+	repo   repository.UserDeviceRepository
+	tokens UserTokenService
+	mail   mailer.Mailer
+	ut     utils.Utils
+	cfg    *config.AppConfig
+}
+
+func NewDeviceService(r repository.UserDeviceRepository, t UserTokenService, m mailer.Mailer, u utils.Utils, c *config.AppConfig) DeviceService {
+	return &deviceService{repo: r, tokens: t, mail: m, ut: u, cfg: c}
+}
+
+// Touch mencatat perangkat dari request saat ini. Nilai bool true berarti perangkat belum
+// pernah dipakai, kecuali perangkat pertama user yang langsung dianggap dikenal
+func (s *deviceService) Touch(ctx context.Context, userID string) (*model.UserDeviceModel, bool, error) {
+	device := s.current(ctx, userID)
+
+	known, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	inserted, err := s.repo.Touch(ctx, device)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return device, inserted && known > 0, nil
+}
+
+// Hold menahan login role tinggi dari perangkat yang belum dikenal dan mengirim link
+// konfirmasi ke email user. Nilai true berarti JWT belum boleh diberikan
+func (s *deviceService) Hold(ctx context.Context, user *model.UserModel) (bool, error) {
+	if !s.cfg.Device.ConfirmNewDevice || !hasAnyRole(user.Roles, s.cfg.Device.ElevatedRoles) {
+		return false, nil
+	}
+
+	device := s.current(ctx, user.ID)
+	if _, err := s.repo.FindByFingerprint(ctx, user.ID, device.Fingerprint); err == nil {
+		return false, nil
+	} else if !apperror.Is(err, codeDeviceNotFound) {
+		return false, err
+	}
+
+	payload, err := json.Marshal(device)
+	if err != nil {
+		return false, apperror.New(apperror.CodeMarshalError, "gagal menyimpan data perangkat", err)
+	}
+	p := string(payload)
+
+	tok, err := s.tokens.Issue(ctx, user.ID, model.TokenPurposeLoginConfirm, &p, s.cfg.Device.ConfirmTTL)
+	if err != nil {
+		return false, err
+	}
+
+	url := fmt.Sprintf("%s?token=%s", s.cfg.Mail.FrontConfirmUrl, tok)
+	body := fmt.Sprintf("<p>Ada percobaan login ke akun Anda dari perangkat yang belum dikenal.</p>"+
+		"<p>Alamat IP: %s<br>Perangkat: %s</p>"+
+		"<p>Jika itu Anda, klik disini untuk melanjutkan login: <a href='%s'>%s</a></p>"+
+		"<p>Jika bukan, abaikan email ini dan segera ganti password Anda.</p>",
+		html.EscapeString(device.IP), html.EscapeString(device.UserAgent), url, url)
+
+	if err := s.mail.Send(user.Email, "Konfirmasi Login Perangkat Baru", body); err != nil {
+		return false, apperror.New(apperror.CodeInternalError, "gagal mengirim email konfirmasi login", err)
+	}
+
+	return true, nil
+}
+
+// Confirm menandai perangkat yang ditahan sebagai perangkat dikenal dan mengembalikan user ID-nya.
+// Perangkat diambil dari data saat login, bukan dari request yang membuka link
+func (s *deviceService) Confirm(ctx context.Context, token string) (string, error) {
+	t, err := s.tokens.Consume(ctx, model.TokenPurposeLoginConfirm, token)
+	if err != nil {
+		return "", err
+	}
+
+	var device model.UserDeviceModel
+	if t.Payload == nil || json.Unmarshal([]byte(*t.Payload), &device) != nil {
+		return "", apperror.New(apperror.CodeTokenInvalid, "data konfirmasi login tidak valid", nil)
+	}
+
+	device.ID = s.ut.GenerateULID()
+	device.UserID = t.UserID
+	if _, err := s.repo.Touch(ctx, &device); err != nil {
+		return "", err
+	}
+
+	return t.UserID, nil
+}
+
+func (s *deviceService) List(ctx context.Context, userID string) ([]response.DeviceResponse, error) {
+	devices, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := s.current(ctx, userID).Fingerprint
+	result := make([]response.DeviceResponse, 0, len(devices))
+	for _, d := range devices {
+		result = append(result, response.DeviceResponse{
+			ID:          d.ID,
+			IP:          d.IP,
+			UserAgent:   d.UserAgent,
+			FirstSeenAt: d.FirstSeenAt,
+			LastSeenAt:  d.LastSeenAt,
+			Current:     d.Fingerprint == current,
+		})
+	}
+
+	return result, nil
+}
+
+// Forget menghapus perangkat, login berikutnya dari perangkat itu dianggap perangkat baru
+func (s *deviceService) Forget(ctx context.Context, userID, deviceID string) error {
+	return s.repo.Delete(ctx, userID, deviceID)
+}
+
+// current mengenali perangkat dari IP dan User-Agent request. User-Agent dikirim bebas oleh klien
+// dan IP hanya bisa dipercaya jika TRUSTED_PROXIES benar, jadi fingerprint ini tidak membuktikan identitas perangkat
+func (s *deviceService) current(ctx context.Context, userID string) *model.UserDeviceModel {
+	info := clientinfo.FromContext(ctx)
+	ua := info.UserAgent
+	if len(ua) > maxUserAgent {
+		ua = ua[:maxUserAgent]
+	}
+
+	return &model.UserDeviceModel{
+		ID:          s.ut.GenerateULID(),
+		UserID:      userID,
+		Fingerprint: s.ut.DeviceFingerprint(info.IP, info.UserAgent),
+		IP:          info.IP,
+		UserAgent:   ua,
+	}
+}
+
+func hasAnyRole(roles []model.RoleModel, names []string) bool {
+	for _, r := range roles {
+		for _, name := range names {
+			if strings.EqualFold(r.Name, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/notify"
+	"github.com/gogaruda/auth/pkg/utils"
+	"html"
+	"log"
+	"strings"
+	"time"
+)
+
+// emailChangeTTL masa berlaku link konfirmasi yang dikirim ke alamat baru
+const emailChangeTTL = 30 * time.Minute
+
+// EmailChangeService mengganti email dalam dua langkah: alamat baru diverifikasi dulu,
+// lalu alamat lama menerima link untuk membatalkan penggantian selama EmailRevertTTL
+type EmailChangeService interface {
+	Request(ctx context.Context, userID string, req *request.EmailChangeRequest) error
+	Confirm(ctx context.Context, token string) error
+	Revert(ctx context.Context, token string) error
+}
+
+type emailChangeService struct {
+	userRepo repository.UserRepository
+	authRepo repository.AuthRepository
+	tokens   UserTokenService
+	notify   NotificationService
+	mail     mailer.Mailer
+	ut       utils.Utils
+	config   *config.AppConfig
+}
+
+func NewEmailChangeService(
+	u repository.UserRepository,
+	a repository.AuthRepository,
+	t UserTokenService,
+	n NotificationService,
+	m mailer.Mailer,
+	ut utils.Utils,
+	c *config.AppConfig,
+) EmailChangeService {
+	return &emailChangeService{userRepo: u, authRepo: a, tokens: t, notify: n, mail: m, ut: ut, config: c}
+}
+
+func (s *emailChangeService) Request(ctx context.Context, userID string, req *request.EmailChangeRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Password != nil && !s.ut.CompareHash(*user.Password, req.Password) {
+		return apperror.New(apperror.CodeInvalidCredential, "password salah", errors.New("password tidak cocok"))
+	}
+
+	if strings.EqualFold(user.Email, req.Email) {
+		return apperror.New(apperror.CodeBadRequest, "email baru sama dengan email sekarang", nil)
+	}
+
+	exists, err := s.authRepo.IsEmailExists(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		// mode hardened: jangan bocorkan bahwa alamat tersebut sudah terdaftar
+		if s.config.Sec.Hardened {
+			return nil
+		}
+		return apperror.New(apperror.CodeEmailConflict, "email sudah digunakan", nil)
+	}
+
+	return s.sendChangeLink(ctx, user, req.Email)
+}
+
+// sendChangeLink mengirim link konfirmasi ke alamat baru, alamat baru baru dipakai setelah link dibuka.
+// Token disimpan sebagai hash di user_tokens dan permintaan sebelumnya tidak berlaku lagi
+func (s *emailChangeService) sendChangeLink(ctx context.Context, user *model.UserModel, newEmail string) error {
+	if err := s.tokens.RevokeAll(ctx, user.ID, model.TokenPurposeEmailChange); err != nil {
+		return err
+	}
+
+	tok, err := s.tokens.Issue(ctx, user.ID, model.TokenPurposeEmailChange, &newEmail, emailChangeTTL)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s?token=%s", s.config.Mail.FrontEmailChangeUrl, tok)
+	body := fmt.Sprintf("<p>Ada permintaan untuk menjadikan alamat ini sebagai email akun Anda.</p>"+
+		"<p>Klik disini untuk konfirmasi: <a href='%s'>%s</a></p><p>Link berlaku selama 30 menit. "+
+		"Abaikan email ini jika Anda tidak merasa memintanya.</p>", url, url)
+
+	if err := s.mail.Send(newEmail, "Konfirmasi Ganti Email", body); err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal mengirim verifikasi email", err)
+	}
+	return nil
+}
+
+// Confirm memindahkan akun ke alamat baru lalu mengirim link pembatalan ke alamat lama
+func (s *emailChangeService) Confirm(ctx context.Context, token string) error {
+	t, err := s.tokens.Consume(ctx, model.TokenPurposeEmailChange, token)
+	if err != nil {
+		return err
+	}
+	if t.Payload == nil {
+		return apperror.New(apperror.CodeTokenInvalid, "token tidak valid", nil)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+
+	newEmail, oldEmail := *t.Payload, user.Email
+	exists, err := s.authRepo.IsEmailExists(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperror.New(apperror.CodeEmailConflict, "email sudah digunakan", nil)
+	}
+
+	if err := s.userRepo.ChangeEmail(ctx, user.ID, newEmail); err != nil {
+		return err
+	}
+
+	revertToken, err := s.tokens.Issue(ctx, user.ID, model.TokenPurposeEmailRevert, &oldEmail, s.config.Account.EmailRevertTTL)
+	if err != nil {
+		log.Printf("[WARN] gagal membuat link pembatalan ganti email user %s: %v", user.ID, err)
+	} else {
+		s.sendRevertNotice(ctx, oldEmail, newEmail, revertToken)
+	}
+
+	user.Email = newEmail
+	s.notify.Notify(ctx, user, notify.EventEmailChanged, map[string]string{"Email lama": oldEmail})
+	return nil
+}
+
+// Revert memulihkan alamat lama dan mengeluarkan semua sesi, dipakai saat penggantian
+// email bukan dilakukan pemilik akun
+func (s *emailChangeService) Revert(ctx context.Context, token string) error {
+	t, err := s.tokens.Consume(ctx, model.TokenPurposeEmailRevert, token)
+	if err != nil {
+		return err
+	}
+	if t.Payload == nil {
+		return apperror.New(apperror.CodeTokenInvalid, "token tidak valid", nil)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user.Email != *t.Payload {
+		if err := s.userRepo.ChangeEmail(ctx, user.ID, *t.Payload); err != nil {
+			return err
+		}
+	}
+
+	for _, purpose := range []string{model.TokenPurposeEmailRevert, model.TokenPurposeEmailChange, model.TokenPurposePasswordReset} {
+		if err := s.tokens.RevokeAll(ctx, user.ID, purpose); err != nil {
+			return err
+		}
+	}
+
+	return s.authRepo.UpdateTokenVersion(user.ID, s.ut.GenerateULID())
+}
+
+// sendRevertNotice selalu dikirim, tidak bergantung flag notifikasi, karena link ini
+// satu-satunya jalan pemilik akun merebut kembali akunnya
+func (s *emailChangeService) sendRevertNotice(ctx context.Context, oldEmail, newEmail, token string) {
+	url := fmt.Sprintf("%s?token=%s", s.config.Mail.FrontEmailRevertUrl, token)
+	body := fmt.Sprintf("<p>Email akun Anda baru saja diganti menjadi <b>%s</b>.</p>"+
+		"<p>Bukan Anda? <a href='%s'>Klik disini</a> untuk membatalkan penggantian dan mengeluarkan semua sesi login. "+
+		"Link berlaku sampai %s.</p>",
+		html.EscapeString(newEmail), url, s.config.Account.EmailRevertTTL)
+
+	go func(ctx context.Context) {
+		if err := s.mail.Send(oldEmail, "Email Akun Diganti", body); err != nil {
+			log.Printf("[WARN] gagal mengirim pemberitahuan ganti email ke alamat lama: %v", err)
+		}
+	}(context.WithoutCancel(ctx))
+}
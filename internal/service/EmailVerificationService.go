@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/config"
@@ -14,7 +15,9 @@ import (
 
 type EmailVerificationService interface {
 	SendVerification(ctx context.Context, user model.UserModel) error
+	ResendVerification(ctx context.Context, userID string) error
 	VerifyToken(ctx context.Context, token string) error
+	VerifyCode(ctx context.Context, userID, code string) error
 }
 
 type emailVerificationService struct {
@@ -42,6 +45,22 @@ func NewEmailVerificationService(
 }
 
 func (s *emailVerificationService) SendVerification(ctx context.Context, user model.UserModel) error {
+	// Kode yang sedang terkunci tidak boleh diakali dengan minta kode baru
+	last, err := s.evRepo.FindLatestByUserID(ctx, user.ID)
+	if err != nil && !apperror.Is(err, "[CODE_NOT_FOUND]") {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var lastCode *otpCode
+	if last != nil {
+		lastCode = emailOTPCode(last)
+	}
+	attempts, err := otpStart(lastCode, now, s.cMail.OTPMaxAttempts, s.cMail.OTPLockout, s.cMail.OTPResendCooldown)
+	if err != nil {
+		return err
+	}
+
 	tok, err := s.mail.GenerateRandomToken(32)
 	if err != nil {
 		return err
@@ -51,15 +70,33 @@ func (s *emailVerificationService) SendVerification(ctx context.Context, user mo
 		ID:        s.ut.GenerateULID(),
 		UserID:    user.ID,
 		Token:     tok,
-		ExpiresAt: time.Now().UTC().Add(30 * time.Minute),
+		Attempts:  attempts,
+		ExpiresAt: now.Add(30 * time.Minute),
+		CreatedAt: now,
+	}
+
+	var code string
+	if s.cMail.SendCode() {
+		code, err = s.ut.GenerateOTP(otpLength)
+		if err != nil {
+			return apperror.New(apperror.CodeInternalError, "gagal membuat kode verifikasi", err)
+		}
+		hashed := s.ut.HashToken(code)
+		ev.Code = &hashed
 	}
 
 	if err := s.evRepo.Create(ctx, ev); err != nil {
 		return err
 	}
 
-	url := fmt.Sprintf("%s?token=%s", s.cMail.FrontVerifyUrl, tok)
-	body := fmt.Sprintf("<p>Klik disini untuk verifikasi email: <a href='%s'>%s</a></p>", url, url)
+	var body string
+	if s.cMail.SendLink() {
+		url := fmt.Sprintf("%s?token=%s", s.cMail.FrontVerifyUrl, tok)
+		body += fmt.Sprintf("<p>Klik disini untuk verifikasi email: <a href='%s'>%s</a></p>", url, url)
+	}
+	if s.cMail.SendCode() {
+		body += fmt.Sprintf("<p>Kode verifikasi email Anda: <b>%s</b></p><p>Kode berlaku selama 30 menit.</p>", code)
+	}
 
 	if err := s.mail.Send(user.Email, "Verifikasi Email", body); err != nil {
 		return apperror.New(apperror.CodeInternalError, "gagal mengirim verifikasi email", err)
@@ -67,6 +104,19 @@ func (s *emailVerificationService) SendVerification(ctx context.Context, user mo
 	return nil
 }
 
+func (s *emailVerificationService) ResendVerification(ctx context.Context, userID string) error {
+	user, err := s.userService.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsVerified {
+		return apperror.New("[EMAIL_ALREADY_VERIFIED]", "email sudah diverifikasi", nil, 400)
+	}
+
+	return s.SendVerification(ctx, *user)
+}
+
 func (s *emailVerificationService) VerifyToken(ctx context.Context, token string) error {
 	ev, err := s.evRepo.FindByToken(ctx, token)
 	if err != nil {
@@ -87,3 +137,29 @@ func (s *emailVerificationService) VerifyToken(ctx context.Context, token string
 
 	return s.evRepo.MarkAsUsed(ctx, ev.ID)
 }
+
+func (s *emailVerificationService) VerifyCode(ctx context.Context, userID, code string) error {
+	if !s.cMail.SendCode() {
+		return apperror.New(apperror.CodeNotImplemented, "verifikasi dengan kode tidak aktif", errors.New("EMAIL_VERIFY_MODE=link"))
+	}
+
+	ev, err := s.evRepo.FindLatestByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkOTP(ctx, s.evRepo, *emailOTPCode(ev), s.ut.HashToken(code), s.cMail.OTPMaxAttempts, s.cMail.OTPLockout); err != nil {
+		return err
+	}
+
+	if err := s.userService.MarkEmailVerified(ctx, ev.UserID); err != nil {
+		return err
+	}
+
+	return s.evRepo.MarkAsUsed(ctx, ev.ID)
+}
+
+func emailOTPCode(ev *model.EmailVerificationModel) *otpCode {
+	return &otpCode{ID: ev.ID, Code: ev.Code, Attempts: ev.Attempts, LockedUntil: ev.LockedUntil,
+		ExpiresAt: ev.ExpiresAt, IsUsed: ev.IsUsed, CreatedAt: ev.CreatedAt}
+}
@@ -6,81 +6,99 @@ import (
 	"errors"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/dto/response"
 	"github.com/gogaruda/auth/internal/model"
 	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/notify"
 	"github.com/gogaruda/auth/pkg/utils"
 	"google.golang.org/api/oauth2/v1"
+	"time"
 )
 
 type GoogleAuthService interface {
 	Login() string
-	Callback(ctx context.Context, code string) (string, error)
+	Callback(ctx context.Context, code string) (*response.LoginResponse, error)
 }
 
 type googleAuthService struct {
 	userRepo repository.UserRepository
 	roleRepo repository.RoleRepository
-	authRepo repository.AuthRepository
+	auth     AuthService
 	cfg      *config.AppConfig
 	ut       utils.Utils
+	canary   CanaryService
+	notify   NotificationService
 }
 
 func NewGoogleAuthService(
 	r repository.UserRepository,
 	rr repository.RoleRepository,
-	au repository.AuthRepository,
+	as AuthService,
 	c *config.AppConfig,
-	u utils.Utils) GoogleAuthService {
+	u utils.Utils,
+	cs CanaryService,
+	n NotificationService) GoogleAuthService {
 	return &googleAuthService{
 		userRepo: r,
 		roleRepo: rr,
 		cfg:      c,
-		ut:       u, authRepo: au}
+		ut:       u, auth: as, canary: cs, notify: n}
 }
 
 func (s *googleAuthService) Login() string {
 	return s.cfg.Google.AuthCodeURL("state-random")
 }
 
-func (s *googleAuthService) Callback(ctx context.Context, code string) (string, error) {
+func (s *googleAuthService) Callback(ctx context.Context, code string) (*response.LoginResponse, error) {
 	token, err := s.cfg.Google.Exchange(ctx, code)
 	if err != nil {
-		return "", apperror.New(apperror.CodeInternalError, "exchange token tidak valid", err)
+		return nil, apperror.New(apperror.CodeInternalError, "exchange token tidak valid", err)
 	}
 
 	client := s.cfg.Google.Client(ctx, token)
 	service, err := oauth2.New(client)
 	if err != nil {
-		return "", apperror.New(apperror.CodeInternalError, "gagal membuat OAuth2 service", err)
+		return nil, apperror.New(apperror.CodeInternalError, "gagal membuat OAuth2 service", err)
 	}
 
 	userInfo, err := service.Userinfo.Get().Do()
 	if err != nil {
-		return "", apperror.New(apperror.CodeInternalError, "gagal mendapatkan user info", err)
+		return nil, apperror.New(apperror.CodeInternalError, "gagal mendapatkan user info", err)
 	}
 
 	email := userInfo.Email
 	var finalUser *model.UserModel
-	newTokenVersion := s.ut.GenerateULID()
+
+	if cred := s.canary.MatchIdentifier(ctx, email); cred != nil {
+		s.canary.Trip(ctx, CanaryTrip{Source: CanarySourceGoogle, Identifier: email, Credential: cred})
+		return nil, errGoogleLoginFailed()
+	}
 
 	// Cari user berdasarkan email
 	user, err := s.userRepo.FindByEmail(ctx, email)
 	switch {
 	case err == nil:
+		// Akun canary tidak boleh ditautkan ke Google
+		if user.IsCanary {
+			s.canary.Trip(ctx, CanaryTrip{Source: CanarySourceGoogle, UserID: user.ID, Identifier: email})
+			return nil, errGoogleLoginFailed()
+		}
+
+		if err := CheckAccountStatus(user, time.Now()); err != nil {
+			return nil, err
+		}
+
 		// User sudah terdaftar
 		if user.GoogleID == nil {
 			user.GoogleID = &userInfo.Id
 			if err := s.userRepo.UpdateGoogleID(ctx, user.ID, *user.GoogleID); err != nil {
-				return "", err
+				return nil, err
 			}
+			s.notify.Notify(ctx, user, notify.EventGoogleLinked, map[string]string{"Akun Google": email})
 		}
 
 		if user.CreatedByAdmin && !user.IsVerified {
-			return "", apperror.New("[EMAIL_NOT_VERIFIED]", "akun harus verifikasi email terlebih dahulu", nil, 403)
-		}
-
-		if err := s.authRepo.UpdateTokenVersion(user.ID, newTokenVersion); err != nil {
-			return "", err
+			return nil, apperror.New("[EMAIL_NOT_VERIFIED]", "akun harus verifikasi email terlebih dahulu", nil, 403)
 		}
 
 		finalUser = user
@@ -88,7 +106,7 @@ func (s *googleAuthService) Callback(ctx context.Context, code string) (string,
 	case errors.Is(err, sql.ErrNoRows):
 		tamuRole, err := s.roleRepo.CheckRoles(ctx, []string{"tamu"})
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		newUser := model.UserModel{
@@ -96,7 +114,7 @@ func (s *googleAuthService) Callback(ctx context.Context, code string) (string,
 			Username:       nil,
 			Email:          email,
 			Password:       nil,
-			TokenVersion:   &newTokenVersion,
+			TokenVersion:   nil,
 			GoogleID:       &userInfo.Id,
 			IsVerified:     true,
 			CreatedByAdmin: false,
@@ -104,27 +122,19 @@ func (s *googleAuthService) Callback(ctx context.Context, code string) (string,
 		}
 
 		if err := s.userRepo.Create(ctx, newUser); err != nil {
-			return "", err
+			return nil, err
 		}
 
 		finalUser = &newUser
 
 	default:
-		return "", apperror.New(apperror.CodeDBError, "gagal mencari user", err)
-	}
-
-	// ambil roles
-	var roles []string
-	for _, r := range finalUser.Roles {
-		roles = append(roles, r.Name)
+		return nil, apperror.New(apperror.CodeDBError, "gagal mencari user", err)
 	}
 
-	finalUser.TokenVersion = &newTokenVersion
-
-	tokenString, err := s.ut.GenerateJWT(finalUser.ID, newTokenVersion, finalUser.IsVerified, roles, s.cfg)
-	if err != nil {
-		return "", apperror.New(apperror.CodeInternalError, "gagal buat JWT", err)
-	}
+	// jalur token sama dengan login password, termasuk konfirmasi perangkat dan kewajiban ganti password
+	return s.auth.IssueToken(ctx, finalUser)
+}
 
-	return tokenString, nil
+func errGoogleLoginFailed() error {
+	return apperror.New(apperror.CodeInvalidCredential, "login dengan google gagal", nil)
 }
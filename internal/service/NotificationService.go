@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/notify"
+	"log"
+	"time"
+)
+
+// NotificationService mengirim email pemberitahuan keamanan ke pemilik akun.
+// Pengiriman berjalan di belakang, kegagalan hanya dicatat ke log
+type NotificationService interface {
+	Notify(ctx context.Context, user *model.UserModel, event notify.Event, details map[string]string)
+}
+
+type notificationService struct {
+	mail     mailer.Mailer
+	renderer notify.Renderer
+	tokens   UserTokenService
+	cfg      *config.AppConfig
+}
+
+func NewNotificationService(m mailer.Mailer, r notify.Renderer, t UserTokenService, c *config.AppConfig) NotificationService {
+	return &notificationService{mail: m, renderer: r, tokens: t, cfg: c}
+}
+
+func (s *notificationService) Notify(ctx context.Context, user *model.UserModel, event notify.Event, details map[string]string) {
+	if !s.enabled(event) {
+		return
+	}
+
+	name := user.Email
+	if user.Username != nil {
+		name = *user.Username
+	}
+
+	// salinan agar pemanggil bebas mengubah user setelah Notify dipanggil
+	userID, to := user.ID, user.Email
+	go func(ctx context.Context) {
+		data := notify.Data{
+			Name:    name,
+			Time:    time.Now().Format("02 Jan 2006 15:04 MST"),
+			Details: details,
+		}
+
+		tok, err := s.tokens.Issue(ctx, userID, model.TokenPurposeSessionRevoke, nil, s.cfg.Notify.RevokeTTL)
+		if err != nil {
+			log.Printf("[WARN] gagal membuat link revoke untuk user %s: %v", userID, err)
+		} else {
+			data.RevokeURL = fmt.Sprintf("%s?token=%s", s.cfg.Mail.FrontRevokeUrl, tok)
+		}
+
+		subject, body, err := s.renderer.Render(event, data)
+		if err != nil {
+			log.Printf("[WARN] gagal render notifikasi %s: %v", event, err)
+			return
+		}
+
+		if err := s.mail.Send(to, subject, body); err != nil {
+			log.Printf("[WARN] gagal mengirim notifikasi %s ke user %s: %v", event, userID, err)
+		}
+	}(context.WithoutCancel(ctx))
+}
+
+func (s *notificationService) enabled(event notify.Event) bool {
+	cfg := s.cfg.Notify
+	switch event {
+	case notify.EventNewLogin:
+		return cfg.NewLogin
+	case notify.EventPasswordChanged:
+		return cfg.PasswordChanged
+	case notify.EventEmailChanged:
+		return cfg.EmailChanged
+	case notify.EventGoogleLinked:
+		return cfg.GoogleLinked
+	case notify.EventRoleChanged:
+		return cfg.RoleChanged
+	case notify.EventMFADisabled:
+		return cfg.MFADisabled
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/notify"
+	"github.com/gogaruda/auth/pkg/password"
+	"github.com/gogaruda/auth/pkg/utils"
+	"log"
+)
+
+type PasswordService interface {
+	ChangePassword(ctx context.Context, userID string, req request.ChangePasswordRequest) error
+	ForgotPassword(ctx context.Context, req request.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req request.ResetPasswordRequest) error
+	AdminResetPassword(ctx context.Context, actor Actor, userID string, req request.AdminResetPasswordRequest) error
+}
+
+type passwordService struct {
+	userRepo repository.UserRepository
+	authRepo repository.AuthRepository
+	tokens   UserTokenService
+	mail     mailer.Mailer
+	policy   password.Policy
+	ut       utils.Utils
+	cfg      *config.AppConfig
+	notify   NotificationService
+}
+
+func NewPasswordService(
+	ur repository.UserRepository,
+	ar repository.AuthRepository,
+	t UserTokenService,
+	m mailer.Mailer,
+	p password.Policy,
+	u utils.Utils,
+	c *config.AppConfig,
+	n NotificationService,
+) PasswordService {
+	return &passwordService{userRepo: ur, authRepo: ar, tokens: t, mail: m, policy: p, ut: u, cfg: c, notify: n}
+}
+
+func (s *passwordService) ChangePassword(ctx context.Context, userID string, req request.ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Akun yang dibuat lewat Google belum punya password lama
+	if user.Password != nil && !s.ut.CompareHash(*user.Password, req.OldPassword) {
+		return apperror.New(apperror.CodeInvalidCredential, "password lama salah", errors.New("password lama tidak cocok"))
+	}
+
+	return s.setPassword(ctx, user, req.NewPassword, false)
+}
+
+func (s *passwordService) ForgotPassword(ctx context.Context, req request.ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Mode hardened menjawab email tidak terdaftar sama seperti yang terdaftar
+			if s.cfg.Sec.Hardened {
+				return nil
+			}
+			return apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", err)
+		}
+		return apperror.New(apperror.CodeDBError, "gagal mencari user", err)
+	}
+
+	// Mode hardened mengerjakan seluruh jalur email terdaftar (cabut, terbitkan, kirim) di belakang,
+	// supaya waktu respon tidak membedakan email terdaftar dan tidak
+	if s.cfg.Sec.Hardened {
+		go func(ctx context.Context) {
+			if err := s.sendResetLink(ctx, user); err != nil {
+				log.Printf("[WARN] gagal mengirim email reset password user %s: %v", user.ID, err)
+			}
+		}(context.WithoutCancel(ctx))
+		return nil
+	}
+
+	return s.sendResetLink(ctx, user)
+}
+
+// sendResetLink mencabut link reset sebelumnya lalu mengirim link reset baru
+func (s *passwordService) sendResetLink(ctx context.Context, user *model.UserModel) error {
+	if err := s.tokens.RevokeAll(ctx, user.ID, model.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	tok, err := s.tokens.Issue(ctx, user.ID, model.TokenPurposePasswordReset, nil, s.cfg.Pass.ResetTTL)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s?token=%s", s.cfg.Mail.FrontResetUrl, tok)
+	body := fmt.Sprintf("<p>Klik disini untuk reset password: <a href='%s'>%s</a></p>"+
+		"<p>Abaikan email ini jika Anda tidak meminta reset password.</p>", url, url)
+
+	if err := s.mail.Send(user.Email, "Reset Password", body); err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal mengirim email reset password", err)
+	}
+
+	return nil
+}
+
+// ResetPassword memvalidasi password baru sebelum token dipakai, supaya password yang ditolak
+// kebijakan tidak menghanguskan link reset
+func (s *passwordService) ResetPassword(ctx context.Context, req request.ResetPasswordRequest) error {
+	t, err := s.tokens.Find(ctx, model.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+
+	keep, err := s.checkPassword(ctx, user, req.NewPassword, false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.tokens.Consume(ctx, model.TokenPurposePasswordReset, req.Token); err != nil {
+		return err
+	}
+
+	return s.storePassword(ctx, user, req.NewPassword, keep, false)
+}
+
+// AdminResetPassword memakai password pilihan admin, user wajib menggantinya saat login berikutnya
+func (s *passwordService) AdminResetPassword(ctx context.Context, actor Actor, userID string, req request.AdminResetPasswordRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkManage(actor, user); err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, req.NewPassword, true)
+}
+
+// setPassword memvalidasi kebijakan password, menyimpan hash baru lalu mengeluarkan semua sesi user.
+// Password pilihan admin (mustChange) tidak dicek ke daftar password bocor, sama seperti saat admin membuat user.
+func (s *passwordService) setPassword(ctx context.Context, user *model.UserModel, newPassword string, mustChange bool) error {
+	keep, err := s.checkPassword(ctx, user, newPassword, mustChange)
+	if err != nil {
+		return err
+	}
+
+	return s.storePassword(ctx, user, newPassword, keep, mustChange)
+}
+
+// checkPassword menjalankan kebijakan password dan cek riwayat, mengembalikan jumlah riwayat yang disimpan
+func (s *passwordService) checkPassword(ctx context.Context, user *model.UserModel, newPassword string, mustChange bool) (int, error) {
+	in := password.Input{Password: newPassword, Email: user.Email, CheckBreach: !mustChange}
+	if user.Username != nil {
+		in.Username = *user.Username
+	}
+	if err := s.policy.Validate(in); err != nil {
+		return 0, err
+	}
+
+	keep := s.cfg.Pass.HistoryCount
+	if user.CreatedByAdmin {
+		keep = s.cfg.Pass.AdminHistoryCount
+	}
+
+	if keep > 0 {
+		history, err := s.authRepo.PasswordHistory(ctx, user.ID, keep)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, old := range history {
+			if s.ut.CompareHash(old, newPassword) {
+				return 0, password.NewPolicyError(password.RuleReused,
+					fmt.Sprintf("password tidak boleh sama dengan %d password terakhir", keep))
+			}
+		}
+	}
+
+	return keep, nil
+}
+
+func (s *passwordService) storePassword(ctx context.Context, user *model.UserModel, newPassword string, keep int, mustChange bool) error {
+	hash, err := s.ut.GenerateHash(newPassword)
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal generate password", err)
+	}
+
+	if err := s.authRepo.ReplacePassword(ctx, user.ID, hash, keep, mustChange); err != nil {
+		return err
+	}
+
+	if err := s.authRepo.UpdateTokenVersion(user.ID, s.ut.GenerateULID()); err != nil {
+		return err
+	}
+
+	s.notify.Notify(ctx, user, notify.EventPasswordChanged, nil)
+	return nil
+}
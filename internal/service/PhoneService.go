@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/sms"
+	"github.com/gogaruda/auth/pkg/utils"
+	"time"
+)
+
+type PhoneService interface {
+	Normalize(phone string) (string, error)
+	RequestVerification(ctx context.Context, userID, phone string) error
+	Verify(ctx context.Context, userID, code string) error
+	SendOTP(ctx context.Context, userID, phone, purpose string) error
+	VerifyOTP(ctx context.Context, userID, purpose, code string) (*model.PhoneVerificationModel, error)
+}
+
+type phoneService struct {
+	pvRepo   repository.PhoneVerificationRepository
+	userRepo repository.UserRepository
+	authRepo repository.AuthRepository
+	sms      sms.SmsSender
+	ut       utils.Utils
+	cfg      config.SmsConfig
+}
+
+func NewPhoneService(
+	pv repository.PhoneVerificationRepository,
+	ur repository.UserRepository,
+	ar repository.AuthRepository,
+	s sms.SmsSender,
+	u utils.Utils,
+	c config.SmsConfig,
+) PhoneService {
+	return &phoneService{pvRepo: pv, userRepo: ur, authRepo: ar, sms: s, ut: u, cfg: c}
+}
+
+func (s *phoneService) Normalize(phone string) (string, error) {
+	normalized, err := s.ut.NormalizePhone(phone)
+	if err != nil {
+		return "", apperror.New("[PHONE_INVALID]", err.Error(), err, 400)
+	}
+	return normalized, nil
+}
+
+func (s *phoneService) RequestVerification(ctx context.Context, userID, phone string) error {
+	normalized, err := s.Normalize(phone)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.authRepo.IsPhoneExists(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperror.New("[PHONE_CONFLICT]", "nomor telepon sudah terdaftar", errors.New("nomor telepon sudah terdaftar"), 409)
+	}
+
+	return s.SendOTP(ctx, userID, normalized, model.PhonePurposeVerify)
+}
+
+func (s *phoneService) Verify(ctx context.Context, userID, code string) error {
+	pv, err := s.VerifyOTP(ctx, userID, model.PhonePurposeVerify, code)
+	if err != nil {
+		return err
+	}
+
+	// Nomor bisa saja sudah diklaim user lain selama kode belum diverifikasi
+	exists, err := s.authRepo.IsPhoneExists(ctx, pv.Phone)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperror.New("[PHONE_CONFLICT]", "nomor telepon sudah terdaftar", errors.New("nomor telepon sudah terdaftar"), 409)
+	}
+
+	return s.userRepo.UpdatePhone(ctx, userID, pv.Phone)
+}
+
+func (s *phoneService) SendOTP(ctx context.Context, userID, phone, purpose string) error {
+	last, err := s.pvRepo.FindLatest(ctx, userID, purpose)
+	if err != nil && !apperror.Is(err, "[CODE_NOT_FOUND]") {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var lastCode *otpCode
+	if last != nil {
+		lastCode = phoneOTPCode(last)
+	}
+	attempts, err := otpStart(lastCode, now, s.cfg.OTPMaxAttempts, s.cfg.OTPLockout, s.cfg.OTPResendCooldown)
+	if err != nil {
+		return err
+	}
+
+	code, err := s.ut.GenerateOTP(otpLength)
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal membuat kode OTP", err)
+	}
+
+	pv := &model.PhoneVerificationModel{
+		ID:        s.ut.GenerateULID(),
+		UserID:    userID,
+		Phone:     phone,
+		Purpose:   purpose,
+		Code:      s.ut.HashToken(code),
+		Attempts:  attempts,
+		ExpiresAt: now.Add(5 * time.Minute),
+		CreatedAt: now,
+	}
+
+	if err := s.pvRepo.Create(ctx, pv); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Kode OTP Anda: %s. Berlaku 5 menit. Jangan berikan kode ini kepada siapapun.", code)
+	if err := s.sms.Send(phone, message); err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal mengirim kode OTP", err)
+	}
+
+	return nil
+}
+
+func (s *phoneService) VerifyOTP(ctx context.Context, userID, purpose, code string) (*model.PhoneVerificationModel, error) {
+	pv, err := s.pvRepo.FindLatest(ctx, userID, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkOTP(ctx, s.pvRepo, *phoneOTPCode(pv), s.ut.HashToken(code), s.cfg.OTPMaxAttempts, s.cfg.OTPLockout); err != nil {
+		return nil, err
+	}
+
+	if err := s.pvRepo.MarkAsUsed(ctx, pv.ID); err != nil {
+		return nil, err
+	}
+
+	return pv, nil
+}
+
+func phoneOTPCode(pv *model.PhoneVerificationModel) *otpCode {
+	return &otpCode{ID: pv.ID, Code: &pv.Code, Attempts: pv.Attempts, LockedUntil: pv.LockedUntil,
+		ExpiresAt: pv.ExpiresAt, IsUsed: pv.IsUsed, CreatedAt: pv.CreatedAt}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/dto/request"
+	"github.com/gogaruda/auth/internal/dto/response"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/avatar"
+	"github.com/gogaruda/auth/pkg/storage"
+	"github.com/gogaruda/auth/pkg/utils"
+	"log"
+	"strings"
+)
+
+const codeProfileNotFound = "[PROFILE_NOT_FOUND]"
+
+type ProfileService interface {
+	Get(ctx context.Context, userID string) (*response.ProfileResponse, error)
+	Create(ctx context.Context, userID string, req *request.ProfileRequest) (*response.ProfileResponse, error)
+	Update(ctx context.Context, userID string, req *request.ProfileUpdateRequest) (*response.ProfileResponse, error)
+	UploadAvatar(ctx context.Context, userID string, data []byte, contentType string) (*response.ProfileResponse, error)
+	DeleteAvatar(ctx context.Context, userID string) (*response.ProfileResponse, error)
+	// CheckManage memastikan actor boleh mengubah profil user lain, lihat checkManage
+	CheckManage(ctx context.Context, actor Actor, userID string) error
+	// ResolveImage mengisi gambar default untuk profil tanpa avatar
+	ResolveImage(p *response.ProfileResponse)
+}
+
+type profileService struct {
+	repo     repository.ProfileRepository
+	userRepo repository.UserRepository
+	store    storage.BlobStore
+	ut       utils.Utils
+	config   config.AvatarConfig
+}
+
+func NewProfileService(r repository.ProfileRepository, u repository.UserRepository, b storage.BlobStore, ut utils.Utils, c config.AvatarConfig) ProfileService {
+	return &profileService{repo: r, userRepo: u, store: b, ut: ut, config: c}
+}
+
+func (s *profileService) Get(ctx context.Context, userID string) (*response.ProfileResponse, error) {
+	profile, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(profile), nil
+}
+
+func (s *profileService) Create(ctx context.Context, userID string, req *request.ProfileRequest) (*response.ProfileResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	profile := newProfileModel(s.ut.GenerateULID(), userID, req)
+	if err := s.repo.Create(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(profile), nil
+}
+
+// Update mengubah field yang dikirim saja, profil dibuat jika belum ada dan full_name diisi
+func (s *profileService) Update(ctx context.Context, userID string, req *request.ProfileUpdateRequest) (*response.ProfileResponse, error) {
+	profile, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil && !apperror.Is(err, codeProfileNotFound) {
+		return nil, err
+	}
+
+	if profile == nil {
+		if req.FullName == nil {
+			return nil, apperror.New(apperror.CodeBadRequest, "nama lengkap wajib diisi untuk profil baru", nil)
+		}
+		return s.Create(ctx, userID, &request.ProfileRequest{FullName: *req.FullName, Address: req.Address, Gender: req.Gender})
+	}
+
+	if req.FullName != nil {
+		profile.FullName = *req.FullName
+	}
+	if req.Address != nil {
+		profile.Address = req.Address
+	}
+	if req.Gender != nil {
+		profile.Gender = req.Gender
+	}
+
+	if err := s.repo.Update(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(profile), nil
+}
+
+// UploadAvatar memproses gambar ke beberapa ukuran, menyimpannya lewat BlobStore lalu
+// mengganti avatar lama. URL ukuran terbesar disimpan di profiles.image.
+func (s *profileService) UploadAvatar(ctx context.Context, userID string, data []byte, contentType string) (*response.ProfileResponse, error) {
+	profile, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if apperror.Is(err, codeProfileNotFound) {
+			return nil, apperror.New(codeProfileNotFound, "lengkapi profil terlebih dahulu sebelum upload avatar", err, 404)
+		}
+		return nil, err
+	}
+
+	if int64(len(data)) > s.config.MaxSize {
+		return nil, apperror.New("[FILE_TOO_LARGE]", fmt.Sprintf("ukuran file maksimal %d KB", s.config.MaxSize/1024), nil, 413)
+	}
+
+	if _, err := avatar.DetectType(data, contentType); err != nil {
+		return nil, apperror.New("[INVALID_IMAGE]", err.Error(), err, 415)
+	}
+
+	variants, err := avatar.Process(data, s.config.Sizes)
+	if err != nil {
+		status := 422
+		if errors.Is(err, avatar.ErrTooLarge) {
+			status = 413
+		}
+		return nil, apperror.New("[INVALID_IMAGE]", err.Error(), err, status)
+	}
+
+	base := fmt.Sprintf("avatars/%s/%s", userID, strings.ToLower(s.ut.GenerateULID()))
+	var stored []string
+	for _, v := range variants {
+		key := avatarKey(base, v.Size)
+		if err := s.store.Put(ctx, key, v.Data, "image/jpeg"); err != nil {
+			s.deleteKeys(ctx, stored)
+			return nil, apperror.New(apperror.CodeDependencyError, "gagal menyimpan avatar", err)
+		}
+		stored = append(stored, key)
+	}
+
+	oldImage := profile.Image
+	image := s.store.URL(stored[len(stored)-1])
+	profile.Image = &image
+	if err := s.repo.Update(ctx, profile); err != nil {
+		s.deleteKeys(ctx, stored)
+		return nil, err
+	}
+
+	s.deleteAvatar(ctx, oldImage)
+	return s.toResponse(profile), nil
+}
+
+// DeleteAvatar menghapus avatar sehingga profil kembali memakai gambar default
+func (s *profileService) DeleteAvatar(ctx context.Context, userID string) (*response.ProfileResponse, error) {
+	profile, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldImage := profile.Image
+	profile.Image = nil
+	if err := s.repo.Update(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	s.deleteAvatar(ctx, oldImage)
+	return s.toResponse(profile), nil
+}
+
+func (s *profileService) CheckManage(ctx context.Context, actor Actor, userID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return checkManage(actor, user)
+}
+
+func (s *profileService) ResolveImage(p *response.ProfileResponse) {
+	if p == nil {
+		return
+	}
+
+	if p.Image == nil || *p.Image == "" {
+		image := s.config.DefaultImage
+		p.Image = &image
+		return
+	}
+
+	// avatar hasil upload punya beberapa ukuran dengan pola nama yang sama
+	key, ok := s.store.Key(*p.Image)
+	if !ok {
+		return
+	}
+	base, ok := avatarBase(key)
+	if !ok {
+		return
+	}
+
+	p.Images = make(map[int]string, len(s.config.Sizes))
+	for _, size := range s.config.Sizes {
+		p.Images[size] = s.store.URL(avatarKey(base, size))
+	}
+}
+
+// deleteAvatar menghapus semua ukuran avatar lama, gambar di luar BlobStore (default/seeder) dibiarkan
+func (s *profileService) deleteAvatar(ctx context.Context, image *string) {
+	if image == nil {
+		return
+	}
+
+	key, ok := s.store.Key(*image)
+	if !ok {
+		return
+	}
+	base, ok := avatarBase(key)
+	if !ok {
+		return
+	}
+
+	keys := make([]string, 0, len(s.config.Sizes))
+	for _, size := range s.config.Sizes {
+		keys = append(keys, avatarKey(base, size))
+	}
+	s.deleteKeys(ctx, keys)
+}
+
+func (s *profileService) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("[WARN] gagal menghapus file %s: %v", key, err)
+		}
+	}
+}
+
+func (s *profileService) toResponse(p *model.ProfileModel) *response.ProfileResponse {
+	res := &response.ProfileResponse{
+		FullName: p.FullName,
+		Address:  p.Address,
+		Gender:   p.Gender,
+		Image:    p.Image,
+	}
+	s.ResolveImage(res)
+	return res
+}
+
+func newProfileModel(id, userID string, req *request.ProfileRequest) *model.ProfileModel {
+	return &model.ProfileModel{
+		ID:       id,
+		UserID:   userID,
+		FullName: req.FullName,
+		Address:  req.Address,
+		Gender:   req.Gender,
+	}
+}
+
+func avatarKey(base string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", base, size)
+}
+
+// avatarBase mengambil bagian key sebelum akhiran ukuran, contoh avatars/<user>/<id>_256.jpg
+func avatarBase(key string) (string, bool) {
+	if !strings.HasPrefix(key, "avatars/") || !strings.HasSuffix(key, ".jpg") {
+		return "", false
+	}
+
+	idx := strings.LastIndex(key, "_")
+	if idx < 0 {
+		return "", false
+	}
+	return key[:idx], true
+}
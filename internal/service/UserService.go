@@ -2,32 +2,82 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
 	"github.com/gogaruda/auth/internal/dto/request"
 	"github.com/gogaruda/auth/internal/dto/response"
 	"github.com/gogaruda/auth/internal/model"
 	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/notify"
+	"github.com/gogaruda/auth/pkg/password"
 	"github.com/gogaruda/auth/pkg/utils"
+	"strings"
+	"time"
 )
 
 type UserService interface {
-	Create(ctx context.Context, user *request.UserCreateRequest) error
-	GetAll(ctx context.Context, limit, offset int) ([]response.UserResponse, int, error)
+	Create(ctx context.Context, actor Actor, user *request.UserCreateRequest) error
+	GetAll(ctx context.Context, actor Actor, req *request.UserListRequest) ([]response.UserResponse, int, error)
+	GetAllByCursor(ctx context.Context, actor Actor, req *request.UserListRequest) ([]response.UserResponse, *response.Cursor, error)
+	FindByID(ctx context.Context, userID string) (*model.UserModel, error)
 	MarkEmailVerified(ctx context.Context, userID string) error
+	Unlock(ctx context.Context, actor Actor, userID string) error
+	Me(ctx context.Context, userID string) (*response.MeResponse, error)
+	UpdateMe(ctx context.Context, userID string, req *request.ProfileUpdateRequest) (*response.MeResponse, error)
+	ChangeUsername(ctx context.Context, userID string, req *request.UsernameChangeRequest) error
+	UsernameHolder(ctx context.Context, username string, at time.Time) (*response.UsernameHistoryResponse, error)
+	Detail(ctx context.Context, userID string) (*response.UserResponse, error)
+	Update(ctx context.Context, actor Actor, userID string, req *request.UserUpdateRequest) (*response.UserResponse, error)
+	Delete(ctx context.Context, actor Actor, userID string) error
+	ChangeStatus(ctx context.Context, actor Actor, userID string, req *request.UserStatusRequest) (*response.UserResponse, error)
+	// PurgeDeleted menghapus permanen user yang masa pemulihannya sudah lewat
+	PurgeDeleted(ctx context.Context) (int, error)
+}
+
+// Actor adalah admin yang sedang melakukan aksi, diambil dari context AuthMiddleware
+type Actor struct {
+	ID    string
+	Roles []string
+}
+
+const roleSuperAdmin = "super admin"
+
+func (a Actor) has(role string) bool {
+	for _, r := range a.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
 }
 
 type userService struct {
-	repo     repository.UserRepository
-	authRepo repository.AuthRepository
-	roleRepo repository.RoleRepository
-	ut       utils.Utils
+	repo       repository.UserRepository
+	authRepo   repository.AuthRepository
+	roleRepo   repository.RoleRepository
+	profile    ProfileService
+	visibility VisibilityService
+	notify     NotificationService
+	tokens     UserTokenService
+	ut         utils.Utils
+	policy     password.Policy
+	config     config.AccountConfig
 }
 
-func NewUserService(r repository.UserRepository, auth repository.AuthRepository, role repository.RoleRepository, ut utils.Utils) UserService {
-	return &userService{repo: r, authRepo: auth, roleRepo: role, ut: ut}
+func NewUserService(r repository.UserRepository, auth repository.AuthRepository, role repository.RoleRepository,
+	profile ProfileService, vis VisibilityService, n NotificationService, t UserTokenService, ut utils.Utils, p password.Policy,
+	c config.AccountConfig) UserService {
+	return &userService{repo: r, authRepo: auth, roleRepo: role, profile: profile, visibility: vis, notify: n, tokens: t, ut: ut,
+		policy: p, config: c}
 }
 
-func (s *userService) Create(ctx context.Context, user *request.UserCreateRequest) error {
+func (s *userService) Create(ctx context.Context, actor Actor, user *request.UserCreateRequest) error {
+	if err := s.policy.Validate(password.Input{Password: user.Password, Username: user.Username, Email: user.Email}); err != nil {
+		return err
+	}
+
 	usernameExists, err := s.authRepo.IsUsernameExists(ctx, user.Username)
 	if err != nil {
 		return err
@@ -49,21 +99,30 @@ func (s *userService) Create(ctx context.Context, user *request.UserCreateReques
 		return err
 	}
 
+	if err := s.checkGrant(actor, nil, roles); err != nil {
+		return err
+	}
+
 	hashPass, err := s.ut.GenerateHash(user.Password)
 	if err != nil {
 		return apperror.New(apperror.CodeInternalError, "gagal generate password", err)
 	}
 
 	userModel := model.UserModel{
-		ID:             s.ut.GenerateULID(),
-		Username:       &user.Username,
-		Email:          user.Email,
-		Password:       &hashPass,
-		TokenVersion:   nil,
-		GoogleID:       nil,
-		IsVerified:     false,
-		CreatedByAdmin: true,
-		Roles:          roles,
+		ID:                 s.ut.GenerateULID(),
+		Username:           &user.Username,
+		Email:              user.Email,
+		Password:           &hashPass,
+		TokenVersion:       nil,
+		GoogleID:           nil,
+		IsVerified:         false,
+		CreatedByAdmin:     true,
+		Roles:              roles,
+		MustChangePassword: true,
+	}
+
+	if user.Profile != nil {
+		userModel.Profile = newProfileModel(s.ut.GenerateULID(), userModel.ID, user.Profile)
 	}
 
 	if err := s.repo.Create(ctx, userModel); err != nil {
@@ -73,8 +132,137 @@ func (s *userService) Create(ctx context.Context, user *request.UserCreateReques
 	return nil
 }
 
-func (s *userService) GetAll(ctx context.Context, limit, offset int) ([]response.UserResponse, int, error) {
-	return s.repo.GetAll(ctx, limit, offset)
+func (s *userService) GetAll(ctx context.Context, actor Actor, req *request.UserListRequest) ([]response.UserResponse, int, error) {
+	filter, err := s.toUserFilter(actor, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.Offset = (req.Page - 1) * req.Limit
+
+	users, total, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.resolveUsers(users)
+	return users, total, nil
+}
+
+// GetAllByCursor pagination keyset berdasarkan ULID. Satu baris ekstra diambil untuk
+// mengetahui apakah masih ada halaman berikutnya, total tidak dihitung.
+func (s *userService) GetAllByCursor(ctx context.Context, actor Actor, req *request.UserListRequest) ([]response.UserResponse, *response.Cursor, error) {
+	keyset := repository.UserKeyset{Desc: true}
+	switch req.Sort {
+	case "", "-created_at":
+	case "created_at":
+		keyset.Desc = false
+	default:
+		return nil, nil, apperror.New(apperror.CodeBadRequest, "pagination cursor hanya mendukung sort created_at atau -created_at", nil)
+	}
+
+	if req.Cursor != nil && *req.Cursor != "" {
+		id, backward, err := decodeCursor(*req.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyset.ID, keyset.Backward = id, backward
+	}
+
+	filter, err := s.toUserFilter(actor, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter.Limit = req.Limit + 1
+
+	users, err := s.repo.GetAllKeyset(ctx, filter, keyset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	more := len(users) > req.Limit
+	if more {
+		// baris ekstra selalu berada di ujung arah pembacaan
+		if keyset.Backward {
+			users = users[1:]
+		} else {
+			users = users[:req.Limit]
+		}
+	}
+
+	cursor := &response.Cursor{}
+	if len(users) > 0 {
+		first, last := users[0].ID, users[len(users)-1].ID
+		if keyset.Backward {
+			if more {
+				cursor.Prev = encodeCursor(first, true)
+			}
+			cursor.Next = encodeCursor(last, false)
+		} else {
+			if more {
+				cursor.Next = encodeCursor(last, false)
+			}
+			if keyset.ID != "" {
+				cursor.Prev = encodeCursor(first, true)
+			}
+		}
+	}
+
+	s.resolveUsers(users)
+	return users, cursor, nil
+}
+
+func (s *userService) resolveUsers(users []response.UserResponse) {
+	for i := range users {
+		s.profile.ResolveImage(users[i].Profile)
+		resolveStatus(&users[i])
+	}
+}
+
+func (s *userService) toUserFilter(actor Actor, req *request.UserListRequest) (repository.UserFilter, error) {
+	hidden, err := s.visibility.HiddenRoles(actor.Roles)
+	if err != nil {
+		return repository.UserFilter{}, err
+	}
+
+	filter := repository.UserFilter{
+		HiddenRoles:    hidden,
+		Search:         strings.TrimSpace(req.Search),
+		Role:           req.Role,
+		Status:         req.Status,
+		IsVerified:     req.IsVerified,
+		CreatedByAdmin: req.CreatedByAdmin,
+		Google:         req.Google,
+		CreatedFrom:    req.CreatedFrom,
+		Sort:           req.Sort,
+		Limit:          req.Limit,
+	}
+	if req.CreatedTo != nil {
+		to := req.CreatedTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &to
+	}
+	return filter, nil
+}
+
+// cursor berisi arah dan ULID batas halaman, di-encode base64 supaya klien memperlakukannya sebagai nilai opaque
+func encodeCursor(id string, backward bool) string {
+	dir := "a"
+	if backward {
+		dir = "b"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(dir + ":" + id))
+}
+
+func decodeCursor(cursor string) (string, bool, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	dir, id, ok := strings.Cut(string(raw), ":")
+	if err != nil || !ok || (dir != "a" && dir != "b") || len(id) != 26 {
+		return "", false, apperror.New(apperror.CodeBadRequest, "cursor tidak valid", err)
+	}
+	return id, dir == "b", nil
+}
+
+func (s *userService) FindByID(ctx context.Context, userID string) (*model.UserModel, error) {
+	return s.repo.FindByID(ctx, userID)
 }
 
 func (s *userService) MarkEmailVerified(ctx context.Context, userID string) error {
@@ -89,3 +277,411 @@ func (s *userService) MarkEmailVerified(ctx context.Context, userID string) erro
 
 	return nil
 }
+
+func (s *userService) Unlock(ctx context.Context, actor Actor, userID string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkManage(actor, user); err != nil {
+		return err
+	}
+
+	return s.authRepo.ResetLoginFailures(ctx, userID)
+}
+
+func (s *userService) Me(ctx context.Context, userID string) (*response.MeResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profile.Get(ctx, userID)
+	if err != nil && !apperror.Is(err, codeProfileNotFound) {
+		return nil, err
+	}
+
+	return toMeResponse(user, profile), nil
+}
+
+func (s *userService) UpdateMe(ctx context.Context, userID string, req *request.ProfileUpdateRequest) (*response.MeResponse, error) {
+	if _, err := s.profile.Update(ctx, userID, req); err != nil {
+		return nil, err
+	}
+
+	return s.Me(ctx, userID)
+}
+
+// ChangeUsername mengganti username dengan jeda minimal antar penggantian.
+// Username yang pernah dipakai siapa pun, termasuk milik sendiri, tidak bisa dipakai lagi.
+func (s *userService) ChangeUsername(ctx context.Context, userID string, req *request.UsernameChangeRequest) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Username != nil && *user.Username == req.Username {
+		return apperror.New(apperror.CodeBadRequest, "username baru sama dengan username sekarang", nil)
+	}
+
+	if s.config.UsernameCooldown > 0 {
+		last, err := s.repo.LastUsernameChange(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if last != nil {
+			if next := last.Add(s.config.UsernameCooldown); time.Now().Before(next) {
+				return apperror.New("[USERNAME_COOLDOWN]",
+					fmt.Sprintf("username baru bisa diganti lagi setelah %s", next.Format("02-01-2006 15:04")), nil, 429)
+			}
+		}
+	}
+
+	exists, err := s.authRepo.IsUsernameExists(ctx, req.Username)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperror.New(apperror.CodeUsernameConflict, "username sudah digunakan", nil)
+	}
+
+	return s.repo.ChangeUsername(ctx, userID, user.Username, req.Username)
+}
+
+// UsernameHolder mencari user yang memegang username pada waktu tertentu
+func (s *userService) UsernameHolder(ctx context.Context, username string, at time.Time) (*response.UsernameHistoryResponse, error) {
+	h, err := s.repo.FindUsernameHistory(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.UsernameHistoryResponse{
+		Username:  h.Username,
+		UserID:    h.UserID,
+		UsedFrom:  h.UsedFrom,
+		UsedUntil: h.UsedUntil,
+		HeldAt: h.UserID != nil &&
+			(h.UsedFrom == nil || !at.Before(*h.UsedFrom)) &&
+			(h.UsedUntil == nil || at.Before(*h.UsedUntil)),
+	}, nil
+}
+
+func (s *userService) Detail(ctx context.Context, userID string) (*response.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toUserResponse(ctx, user)
+}
+
+// Update mengubah username, email, status verifikasi dan role. Perubahan yang mempengaruhi isi token
+// (role dan status verifikasi) membuat semua token user tersebut tidak berlaku lagi.
+func (s *userService) Update(ctx context.Context, actor Actor, userID string, req *request.UserUpdateRequest) (*response.UserResponse, error) {
+	before, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkManage(actor, before); err != nil {
+		return nil, err
+	}
+
+	if before.Status == model.UserStatusDeleted {
+		return nil, apperror.New(codeAccountDeleted, "user sudah dihapus, pulihkan terlebih dahulu", nil, 409)
+	}
+
+	after := *before
+	if req.Username != nil && (before.Username == nil || *before.Username != *req.Username) {
+		exists, err := s.authRepo.IsUsernameExists(ctx, *req.Username)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, apperror.New(apperror.CodeUsernameConflict, "username sudah digunakan", nil)
+		}
+		after.Username = req.Username
+	}
+
+	if req.Email != nil && !strings.EqualFold(before.Email, *req.Email) {
+		exists, err := s.authRepo.IsEmailExists(ctx, *req.Email)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, apperror.New(apperror.CodeEmailConflict, "email sudah digunakan", nil)
+		}
+		// alamat baru belum terbukti milik user kecuali admin menyatakan sebaliknya
+		after.Email = *req.Email
+		after.IsVerified = false
+	}
+
+	if req.IsVerified != nil {
+		after.IsVerified = *req.IsVerified
+	}
+
+	if req.Roles != nil {
+		if len(req.Roles) == 0 {
+			return nil, apperror.New(apperror.CodeBadRequest, "user minimal memiliki satu role", nil)
+		}
+
+		roles, err := s.roleRepo.CheckRoles(ctx, req.Roles)
+		if err != nil {
+			return nil, err
+		}
+		after.Roles = roles
+
+		if err := s.checkGrant(actor, before.Roles, after.Roles); err != nil {
+			return nil, err
+		}
+		if actor.ID == userID && hasRole(before.Roles, roleSuperAdmin) && !hasRole(after.Roles, roleSuperAdmin) {
+			return nil, apperror.New(apperror.CodeBadRequest, "tidak bisa mencabut role super admin milik sendiri", nil)
+		}
+	}
+
+	after.TokenVersion = nil
+	rolesChanged := !sameRoleNames(before.Roles, after.Roles)
+	if rolesChanged || before.IsVerified != after.IsVerified {
+		version := s.ut.GenerateULID()
+		after.TokenVersion = &version
+	}
+
+	if err := s.repo.Update(ctx, before, &after); err != nil {
+		return nil, err
+	}
+
+	if before.Email != after.Email {
+		// sama seperti ganti email oleh user: link reset password dan permintaan ganti email yang tertunda tidak berlaku lagi
+		for _, purpose := range []string{model.TokenPurposePasswordReset, model.TokenPurposeEmailChange} {
+			if err := s.tokens.RevokeAll(ctx, userID, purpose); err != nil {
+				return nil, err
+			}
+		}
+		s.notify.Notify(ctx, before, notify.EventEmailChanged, map[string]string{"Email baru": after.Email})
+	}
+
+	if rolesChanged {
+		s.notify.Notify(ctx, &after, notify.EventRoleChanged, map[string]string{"Role": strings.Join(roleNames(after.Roles), ", ")})
+	}
+
+	return s.toUserResponse(ctx, &after)
+}
+
+// Delete hanya menandai user sebagai deleted dan mengeluarkan semua sesinya, data baru
+// dihapus permanen oleh PurgeDeleted setelah DeletedRetention. Admin tidak bisa menghapus
+// dirinya sendiri maupun super admin.
+func (s *userService) Delete(ctx context.Context, actor Actor, userID string) error {
+	if actor.ID == userID {
+		return apperror.New(apperror.CodeBadRequest, "tidak bisa menghapus akun sendiri", nil)
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkManage(actor, user); err != nil {
+		return err
+	}
+
+	if user.Status == model.UserStatusDeleted {
+		return nil
+	}
+
+	version := s.ut.GenerateULID()
+	return s.repo.UpdateStatus(ctx, userID, model.UserStatusDeleted, nil, nil, &version)
+}
+
+// ChangeStatus menangguhkan, menonaktifkan atau mengaktifkan kembali akun. Status selain
+// active langsung mengeluarkan semua sesi user.
+func (s *userService) ChangeStatus(ctx context.Context, actor Actor, userID string, req *request.UserStatusRequest) (*response.UserResponse, error) {
+	if actor.ID == userID {
+		return nil, apperror.New(apperror.CodeBadRequest, "tidak bisa mengubah status akun sendiri", nil)
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkManage(actor, user); err != nil {
+		return nil, err
+	}
+
+	if user.Status == model.UserStatusDeleted {
+		if req.Status != model.UserStatusActive {
+			return nil, apperror.New(codeAccountDeleted, "user sudah dihapus, pulihkan terlebih dahulu", nil, 409)
+		}
+		if user.DeletedAt != nil && time.Since(*user.DeletedAt) > s.config.DeletedRetention {
+			return nil, apperror.New(codeAccountDeleted, "masa pemulihan user sudah lewat", nil, 410)
+		}
+	}
+
+	var reason, version *string
+	var until *time.Time
+	if req.Status != model.UserStatusActive {
+		if req.Status == model.UserStatusSuspended {
+			if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+				return nil, apperror.New(apperror.CodeBadRequest, "alasan suspend wajib diisi", nil)
+			}
+			if req.Until != nil && !req.Until.After(time.Now()) {
+				return nil, apperror.New(apperror.CodeBadRequest, "batas akhir suspend harus di masa depan", nil)
+			}
+			until = req.Until
+		}
+
+		v := s.ut.GenerateULID()
+		reason, version = req.Reason, &v
+	}
+
+	if err := s.repo.UpdateStatus(ctx, userID, req.Status, reason, until, version); err != nil {
+		return nil, err
+	}
+
+	user.Status, user.StatusReason, user.SuspendedUntil, user.DeletedAt = req.Status, reason, until, nil
+	return s.toUserResponse(ctx, user)
+}
+
+func (s *userService) PurgeDeleted(ctx context.Context) (int, error) {
+	ids, err := s.repo.FindDeletedBefore(ctx, time.Now().Add(-s.config.DeletedRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		// file avatar tidak ikut terhapus oleh cascade
+		if _, err := s.profile.DeleteAvatar(ctx, id); err != nil && !apperror.Is(err, codeProfileNotFound) {
+			return i, err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (s *userService) toUserResponse(ctx context.Context, user *model.UserModel) (*response.UserResponse, error) {
+	profile, err := s.profile.Get(ctx, user.ID)
+	if err != nil && !apperror.Is(err, codeProfileNotFound) {
+		return nil, err
+	}
+
+	res := &response.UserResponse{
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		GoogleID:       user.GoogleID,
+		IsVerified:     user.IsVerified,
+		CreatedByAdmin: user.CreatedByAdmin,
+		FailedLogins:   user.FailedLoginAttempts,
+		Roles:          []response.RoleResponse{},
+		Profile:        profile,
+		Status:         user.Status,
+		StatusReason:   user.StatusReason,
+		SuspendedUntil: user.SuspendedUntil,
+	}
+	resolveStatus(res)
+
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		res.IsLocked = true
+		res.LockedUntil = user.LockedUntil
+	}
+
+	for _, role := range user.Roles {
+		res.Roles = append(res.Roles, response.RoleResponse{ID: role.ID, Name: role.Name})
+	}
+
+	return res, nil
+}
+
+// resolveStatus menampilkan suspend yang sudah berakhir sebagai active
+func resolveStatus(res *response.UserResponse) {
+	user := model.UserModel{Status: res.Status, SuspendedUntil: res.SuspendedUntil}
+	if res.Status = user.EffectiveStatus(time.Now()); res.Status == model.UserStatusActive {
+		res.StatusReason, res.SuspendedUntil = nil, nil
+	}
+}
+
+// checkGrant mencegah actor memberikan role super admin tanpa menjadi super admin, juga role yang
+// tersembunyi darinya karena user tersebut akan hilang dari pandangannya. Role yang sudah dimiliki tidak dicek.
+func (s *userService) checkGrant(actor Actor, current, granted []model.RoleModel) error {
+	if !actor.has(roleSuperAdmin) && hasRole(granted, roleSuperAdmin) && !hasRole(current, roleSuperAdmin) {
+		return apperror.New("[USER_PROTECTED]", "hanya super admin yang bisa memberikan role super admin", nil, 403)
+	}
+
+	hidden, err := s.visibility.HiddenRoles(actor.Roles)
+	if err != nil {
+		return err
+	}
+	for _, h := range hidden {
+		if hasRole(granted, h) && !hasRole(current, h) {
+			return apperror.New("[USER_PROTECTED]", fmt.Sprintf("Anda tidak berhak memberikan role %s", h), nil, 403)
+		}
+	}
+	return nil
+}
+
+// checkManage mencegah admin biasa mengubah atau menghapus akun super admin
+func checkManage(actor Actor, target *model.UserModel) error {
+	if hasRole(target.Roles, roleSuperAdmin) && !actor.has(roleSuperAdmin) {
+		return apperror.New("[USER_PROTECTED]", "Anda tidak berhak mengubah akun super admin", nil, 403)
+	}
+	return nil
+}
+
+func hasRole(roles []model.RoleModel, name string) bool {
+	for _, role := range roles {
+		if strings.EqualFold(role.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func roleNames(roles []model.RoleModel) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func sameRoleNames(a, b []model.RoleModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, role := range a {
+		if !hasRole(b, role.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+func toMeResponse(user *model.UserModel, profile *response.ProfileResponse) *response.MeResponse {
+	me := &response.MeResponse{
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		IsVerified:         user.IsVerified,
+		CreatedByAdmin:     user.CreatedByAdmin,
+		MustChangePassword: user.MustChangePassword,
+		Roles:              []string{},
+		Profile:            profile,
+		Identities: response.IdentityResponse{
+			Password: user.Password != nil,
+			Google:   user.GoogleID != nil,
+			Phone:    user.Phone != nil,
+		},
+	}
+
+	for _, role := range user.Roles {
+		me.Roles = append(me.Roles, role.Name)
+	}
+
+	return me
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/model"
+	"github.com/gogaruda/auth/internal/repository"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"github.com/gogaruda/auth/pkg/utils"
+	"time"
+)
+
+// UserTokenService mengelola token sekali pakai (reset password, dsb) yang dikirim lewat email
+type UserTokenService interface {
+	Issue(ctx context.Context, userID, purpose string, payload *string, ttl time.Duration) (string, error)
+	// Find memeriksa token tanpa memakainya, dipakai saat input lain perlu divalidasi dulu
+	Find(ctx context.Context, purpose, token string) (*model.UserTokenModel, error)
+	Consume(ctx context.Context, purpose, token string) (*model.UserTokenModel, error)
+	RevokeAll(ctx context.Context, userID, purpose string) error
+}
+
+type userTokenService struct {
+	repo repository.UserTokenRepository
+	mail mailer.Mailer
+	ut   utils.Utils
+}
+
+func NewUserTokenService(r repository.UserTokenRepository, m mailer.Mailer, u utils.Utils) UserTokenService {
+	return &userTokenService{repo: r, mail: m, ut: u}
+}
+
+func (s *userTokenService) Issue(ctx context.Context, userID, purpose string, payload *string, ttl time.Duration) (string, error) {
+	tok, err := s.mail.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	t := &model.UserTokenModel{
+		ID:        s.ut.GenerateULID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Token:     s.ut.HashToken(tok),
+		Payload:   payload,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+
+	if err := s.repo.Create(ctx, t); err != nil {
+		return "", err
+	}
+
+	return tok, nil
+}
+
+func (s *userTokenService) Find(ctx context.Context, purpose, token string) (*model.UserTokenModel, error) {
+	t, err := s.repo.FindByToken(ctx, purpose, s.ut.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if t.UsedAt != nil {
+		return nil, apperror.New("[TOKEN_USED]", "token sudah digunakan", nil, 400)
+	}
+
+	if time.Now().UTC().After(t.ExpiresAt) {
+		return nil, apperror.New("[TOKEN_EXPIRED]", "token sudah kadaluarsa", nil, 400)
+	}
+
+	return t, nil
+}
+
+func (s *userTokenService) Consume(ctx context.Context, purpose, token string) (*model.UserTokenModel, error) {
+	t, err := s.Find(ctx, purpose, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.MarkAsUsed(ctx, t.ID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *userTokenService) RevokeAll(ctx context.Context, userID, purpose string) error {
+	return s.repo.RevokeByUser(ctx, userID, purpose)
+}
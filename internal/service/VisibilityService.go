@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/internal/repository"
+	"sort"
+	"strings"
+)
+
+// VisibilityService menentukan user mana yang boleh dilihat dan dikelola admin berdasarkan role
+type VisibilityService interface {
+	// HiddenRoles role target yang tidak boleh dilihat pemilik viewerRoles
+	HiddenRoles(viewerRoles []string) ([]string, error)
+	// Check menjawab user tidak ditemukan jika target tersembunyi, supaya keberadaannya tidak bocor
+	Check(ctx context.Context, viewerRoles []string, userID string) error
+}
+
+type visibilityService struct {
+	repo   repository.UserRepository
+	config config.VisibilityConfig
+}
+
+func NewVisibilityService(r repository.UserRepository, c config.VisibilityConfig) VisibilityService {
+	return &visibilityService{repo: r, config: c}
+}
+
+// HiddenRoles memakai role pelihat yang paling longgar: role target hanya tersembunyi
+// jika tersembunyi dari semua role pelihat yang tercantum di kebijakan
+func (s *visibilityService) HiddenRoles(viewerRoles []string) ([]string, error) {
+	var hidden map[string]bool
+	for _, role := range viewerRoles {
+		list, ok := s.config.HiddenRoles[strings.ToLower(role)]
+		if !ok {
+			continue
+		}
+
+		set := make(map[string]bool, len(list))
+		for _, h := range list {
+			set[h] = true
+		}
+
+		if hidden == nil {
+			hidden = set
+			continue
+		}
+		for h := range hidden {
+			if !set[h] {
+				delete(hidden, h)
+			}
+		}
+	}
+
+	if hidden == nil {
+		return nil, apperror.New("[USER_LIST_FORBIDDEN]", "role Anda tidak diizinkan melihat data user", nil, 403)
+	}
+
+	roles := make([]string, 0, len(hidden))
+	for h := range hidden {
+		roles = append(roles, h)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+func (s *visibilityService) Check(ctx context.Context, viewerRoles []string, userID string) error {
+	hidden, err := s.HiddenRoles(viewerRoles)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// akun canary juga tidak pernah tampil di daftar user
+	if user.IsCanary {
+		return apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", nil)
+	}
+
+	for _, role := range user.Roles {
+		for _, h := range hidden {
+			if strings.EqualFold(role.Name, h) {
+				return apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", nil)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"time"
+)
+
+const otpLength = 6
+
+// otpAttempts adalah bagian repository kode OTP yang dipakai checkOTP
+type otpAttempts interface {
+	ReserveAttempt(ctx context.Context, id string, max int) (int, error)
+	Lock(ctx context.Context, id string, until time.Time) error
+}
+
+// otpCode adalah kode OTP tersimpan, baik dari verifikasi email maupun telepon
+type otpCode struct {
+	ID          string
+	Code        *string
+	Attempts    int
+	LockedUntil *time.Time
+	ExpiresAt   time.Time
+	IsUsed      bool
+	CreatedAt   time.Time
+}
+
+// otpStart menentukan jumlah percobaan awal kode baru dari kode terakhir user untuk tujuan yang sama.
+// Percobaan gagal terbawa ke kode berikutnya dan baru kembali nol setelah kode dipakai atau
+// masa kunci lewat, sehingga minta kode baru tidak memberi jatah tebakan baru.
+func otpStart(last *otpCode, now time.Time, max int, lockout, cooldown time.Duration) (int, error) {
+	if last == nil {
+		return 0, nil
+	}
+
+	if last.LockedUntil != nil && now.Before(*last.LockedUntil) {
+		return 0, errCodeLocked()
+	}
+
+	if next := last.CreatedAt.Add(cooldown); now.Before(next) {
+		wait := int(next.Sub(now).Seconds()) + 1
+		return 0, apperror.New("[CODE_COOLDOWN]", fmt.Sprintf("tunggu %d detik sebelum meminta kode baru", wait), nil, 429)
+	}
+
+	switch {
+	case last.IsUsed, last.LockedUntil != nil:
+		return 0, nil
+	case last.Attempts >= max:
+		// jatah habis tapi kunci gagal dicatat, anggap terkunci sejak kode dibuat
+		if now.Before(last.CreatedAt.Add(lockout)) {
+			return 0, errCodeLocked()
+		}
+		return 0, nil
+	}
+	return last.Attempts, nil
+}
+
+// checkOTP mengecek kode OTP dan memakai satu jatah percobaan secara atomik sebelum membandingkan,
+// kode yang lolos belum ditandai terpakai
+func checkOTP(ctx context.Context, repo otpAttempts, c otpCode, hashed string, max int, lockout time.Duration) error {
+	now := time.Now().UTC()
+	if c.IsUsed {
+		return apperror.New("[CODE_USED]", "kode sudah digunakan", nil, 400)
+	}
+
+	if c.LockedUntil != nil && now.Before(*c.LockedUntil) {
+		return errCodeLocked()
+	}
+
+	if c.Code == nil || c.Attempts >= max {
+		return errCodeInvalidated()
+	}
+
+	if now.After(c.ExpiresAt) {
+		return apperror.New("[CODE_EXPIRED]", "kode sudah kadaluarsa", nil, 400)
+	}
+
+	// jatah percobaan dipakai dulu, supaya tebakan paralel tidak melewati batas
+	attempts, err := repo.ReserveAttempt(ctx, c.ID, max)
+	if err != nil {
+		return err
+	}
+	if attempts == 0 {
+		return errCodeInvalidated()
+	}
+
+	if subtle.ConstantTimeCompare([]byte(*c.Code), []byte(hashed)) != 1 {
+		if attempts >= max {
+			if err := repo.Lock(ctx, c.ID, now.Add(lockout)); err != nil {
+				return err
+			}
+			return errCodeLocked()
+		}
+		return apperror.New("[CODE_INVALID]", fmt.Sprintf("kode salah, sisa percobaan %d kali", max-attempts), nil, 400)
+	}
+
+	return nil
+}
+
+func errCodeLocked() error {
+	return apperror.New("[CODE_LOCKED]", "terlalu banyak percobaan, silahkan coba lagi nanti", nil, 429)
+}
+
+func errCodeInvalidated() error {
+	return apperror.New("[CODE_INVALIDATED]", "kode sudah tidak berlaku, silahkan minta kode baru", nil, 400)
+}
@@ -0,0 +1,55 @@
+package alert
+
+import (
+	"context"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"time"
+)
+
+type Severity string
+
+const (
+	SeverityInfo Severity = "info"
+	SeverityHigh Severity = "high"
+)
+
+type Event struct {
+	Type     string            `json:"type"`
+	Severity Severity          `json:"severity"`
+	Message  string            `json:"message"`
+	Time     time.Time         `json:"time"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+type Alerter interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// NewAlerter selalu mencatat ke log, email dan webhook hanya aktif jika dikonfigurasi
+func NewAlerter(c config.AlertConfig, m mailer.Mailer) Alerter {
+	alerters := []Alerter{NewLogAlerter()}
+	if len(c.Emails) > 0 {
+		alerters = append(alerters, NewEmailAlerter(m, c.Emails))
+	}
+	if c.WebhookURL != "" {
+		alerters = append(alerters, NewWebhookAlerter(c.WebhookURL, c.WebhookSecret, c.WebhookTimeout))
+	}
+
+	return &multiAlerter{alerters: alerters}
+}
+
+type multiAlerter struct {
+	alerters []Alerter
+}
+
+// Send meneruskan event ke semua alerter, satu yang gagal tidak menghentikan yang lain
+func (a *multiAlerter) Send(ctx context.Context, e Event) error {
+	var firstErr error
+	for _, al := range a.alerters {
+		if err := al.Send(ctx, e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,49 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/auth/pkg/mailer"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+type emailAlerter struct {
+	mail       mailer.Mailer
+	recipients []string
+}
+
+func NewEmailAlerter(m mailer.Mailer, recipients []string) Alerter {
+	return &emailAlerter{mail: m, recipients: recipients}
+}
+
+func (a *emailAlerter) Send(_ context.Context, e Event) error {
+	subject := fmt.Sprintf("[%s] %s", strings.ToUpper(string(e.Severity)), e.Type)
+	body := emailBody(e)
+
+	for _, to := range a.recipients {
+		if err := a.mail.Send(to, subject, body); err != nil {
+			return apperror.New(apperror.CodeInternalError, "gagal mengirim email alert", err)
+		}
+	}
+	return nil
+}
+
+func emailBody(e Event) string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<p><b>%s</b></p><p>%s</p><table>", html.EscapeString(e.Message), e.Time.Format(time.RFC1123))
+	for _, k := range keys {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td></tr>", html.EscapeString(k), html.EscapeString(e.Fields[k]))
+	}
+	b.WriteString("</table>")
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package alert
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+)
+
+type logAlerter struct{}
+
+func NewLogAlerter() Alerter {
+	return &logAlerter{}
+}
+
+func (a *logAlerter) Send(_ context.Context, e Event) error {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(quote(e.Fields[k]))
+	}
+
+	log.Printf("[ALERT] severity=%s type=%s message=%s%s", e.Severity, e.Type, quote(e.Message), b.String())
+	return nil
+}
+
+func quote(s string) string {
+	if strings.ContainsAny(s, " \t\"=") {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
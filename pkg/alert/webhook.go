@@ -0,0 +1,60 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"net/http"
+	"time"
+)
+
+// webhookAlerter mengirim event sebagai JSON. Jika secret diisi, body ditandatangani
+// HMAC-SHA256 pada header X-Alert-Signature agar penerima bisa memverifikasi
+type webhookAlerter struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookAlerter(url, secret string, timeout time.Duration) Alerter {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &webhookAlerter{url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (a *webhookAlerter) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return apperror.New(apperror.CodeMarshalError, "gagal encode alert", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal membuat request webhook", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if a.secret != "" {
+		mac := hmac.New(sha256.New, []byte(a.secret))
+		mac.Write(body)
+		req.Header.Set("X-Alert-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return apperror.New(apperror.CodeDependencyError, "gagal mengirim webhook alert", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return apperror.New(apperror.CodeDependencyError, "webhook alert ditolak",
+			fmt.Errorf("status %d", resp.StatusCode))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+)
+
+// maxPixels mencegah decompression bomb: file kecil dengan dimensi raksasa
+const maxPixels = 40_000_000
+
+var (
+	ErrUnsupportedType = errors.New("tipe file tidak didukung, gunakan jpeg, png atau gif")
+	ErrTypeMismatch    = errors.New("isi file tidak sesuai dengan tipe yang dikirim")
+	ErrInvalidImage    = errors.New("file gambar rusak atau tidak bisa dibaca")
+	ErrTooLarge        = errors.New("dimensi gambar terlalu besar")
+)
+
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type Variant struct {
+	Size int
+	Data []byte
+}
+
+// DetectType memeriksa magic bytes file dan mencocokkannya dengan Content-Type yang dikirim client
+func DetectType(data []byte, declared string) (string, error) {
+	detected := http.DetectContentType(data)
+	if !allowedTypes[detected] {
+		return "", ErrUnsupportedType
+	}
+
+	declared = strings.ToLower(strings.TrimSpace(strings.Split(declared, ";")[0]))
+	if declared == "image/jpg" {
+		declared = "image/jpeg"
+	}
+	if declared != "" && declared != "application/octet-stream" && declared != detected {
+		return "", ErrTypeMismatch
+	}
+
+	return detected, nil
+}
+
+// Process memotong gambar menjadi persegi di tengah lalu membuat satu JPEG per ukuran.
+// Gambar di-encode ulang dari piksel sehingga EXIF dan metadata lain ikut terbuang,
+// orientasi EXIF diterapkan lebih dulu supaya foto dari kamera tidak terbalik.
+func Process(data []byte, sizes []int) ([]Variant, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	src := toRGBA(img)
+	if format == "jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+	src = cropSquare(src)
+
+	variants := make([]Variant, 0, len(sizes))
+	for _, size := range sizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resize(src, size), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("encode avatar %dpx gagal: %w", size, err)
+		}
+		variants = append(variants, Variant{Size: size, Data: buf.Bytes()})
+	}
+
+	return variants, nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	// latar putih untuk PNG/GIF transparan karena JPEG tidak punya alpha
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+func cropSquare(img *image.RGBA) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	side := min(w, h)
+	x0, y0 := (w-side)/2, (h-side)/2
+	return img.SubImage(image.Rect(x0, y0, x0+side, y0+side)).(*image.RGBA)
+}
+
+// resize memakai rata-rata area (box filter), cukup untuk memperkecil foto profil.
+// Gambar yang lebih kecil dari target diperbesar dengan nearest neighbour.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		sy0 := y * sh / size
+		sy1 := max((y+1)*sh/size, sy0+1)
+		for x := 0; x < size; x++ {
+			sx0 := x * sw / size
+			sx1 := max((x+1)*sw/size, sx0+1)
+
+			var r, g, bl, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				off := src.PixOffset(b.Min.X+sx0, b.Min.Y+sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					bl += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,122 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+var bloomMagic = [4]byte{'B', 'R', 'F', '1'}
+
+// BloomFilter menyimpan digest SHA-1 password yang bocor.
+// Index bit diturunkan dari digest dengan double hashing, jadi tidak perlu hash tambahan.
+type BloomFilter struct {
+	m    uint64
+	k    uint32
+	n    uint64
+	bits []uint64
+}
+
+// NewBloomFilter menghitung ukuran filter untuk n item dengan tingkat false positive fpRate
+func NewBloomFilter(n uint64, fpRate float64) *BloomFilter {
+	if n == 0 {
+		n = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.001
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+
+	return &BloomFilter{m: m, k: k, bits: make([]uint64, (m+63)/64)}
+}
+
+func (b *BloomFilter) Add(digest [sha1.Size]byte) {
+	h1, h2 := split(digest)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		idx := (h1 + i*h2) % b.m
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+	b.n++
+}
+
+func (b *BloomFilter) Test(digest [sha1.Size]byte) bool {
+	h1, h2 := split(digest)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		idx := (h1 + i*h2) % b.m
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count adalah jumlah item yang sudah dimasukkan
+func (b *BloomFilter) Count() uint64 {
+	return b.n
+}
+
+func (b *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, 4+8+4+8)
+	copy(header, bloomMagic[:])
+	binary.BigEndian.PutUint64(header[4:], b.m)
+	binary.BigEndian.PutUint32(header[12:], b.k)
+	binary.BigEndian.PutUint64(header[16:], b.n)
+
+	written, err := w.Write(header)
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+
+	buf := make([]byte, 8)
+	for _, word := range b.bits {
+		binary.BigEndian.PutUint64(buf, word)
+		written, err := w.Write(buf)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, 4+8+4+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if [4]byte(header[:4]) != bloomMagic {
+		return nil, errors.New("file bukan bloom filter breach")
+	}
+
+	b := &BloomFilter{
+		m: binary.BigEndian.Uint64(header[4:]),
+		k: binary.BigEndian.Uint32(header[12:]),
+		n: binary.BigEndian.Uint64(header[16:]),
+	}
+	if b.m == 0 || b.k == 0 {
+		return nil, errors.New("header bloom filter tidak valid")
+	}
+
+	b.bits = make([]uint64, (b.m+63)/64)
+	buf := make([]byte, 8)
+	for i := range b.bits {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		b.bits[i] = binary.BigEndian.Uint64(buf)
+	}
+
+	return b, nil
+}
+
+func split(digest [sha1.Size]byte) (uint64, uint64) {
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	return h1, h2
+}
@@ -0,0 +1,80 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+	"os"
+)
+
+type Checker interface {
+	IsBreached(password string) bool
+}
+
+type bloomChecker struct {
+	filter *BloomFilter
+}
+
+type noopChecker struct{}
+
+// NewChecker memuat bloom filter jadi (BREACH_FILTER_PATH) atau membangunnya dari
+// daftar SHA-1 (BREACH_LIST_PATH). Tanpa keduanya, screening tidak aktif.
+func NewChecker(c config.BreachConfig) (Checker, error) {
+	switch {
+	case c.FilterPath != "":
+		f, err := os.Open(c.FilterPath)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuka bloom filter breach: %w", err)
+		}
+		defer f.Close()
+
+		filter, err := ReadBloomFilter(f)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca bloom filter breach: %w", err)
+		}
+		return &bloomChecker{filter: filter}, nil
+
+	case c.ListPath != "":
+		filter, err := BuildFromFile(c.ListPath, c.MinCount, c.FPRate)
+		if err != nil {
+			return nil, err
+		}
+		return &bloomChecker{filter: filter}, nil
+
+	default:
+		return noopChecker{}, nil
+	}
+}
+
+// BuildFromFile membaca dump dua kali: menghitung jumlah hash lalu mengisi filter
+func BuildFromFile(path string, minCount int, fpRate float64) (*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka daftar breach: %w", err)
+	}
+	defer f.Close()
+
+	var n uint64
+	if err := ScanHIBP(f, minCount, func([sha1.Size]byte) { n++ }); err != nil {
+		return nil, fmt.Errorf("gagal membaca daftar breach: %w", err)
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	filter := NewBloomFilter(n, fpRate)
+	if err := ScanHIBP(f, minCount, filter.Add); err != nil {
+		return nil, fmt.Errorf("gagal membaca daftar breach: %w", err)
+	}
+
+	return filter, nil
+}
+
+func (c *bloomChecker) IsBreached(password string) bool {
+	return c.filter.Test(sha1.Sum([]byte(password)))
+}
+
+func (noopChecker) IsBreached(string) bool {
+	return false
+}
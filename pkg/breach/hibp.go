@@ -0,0 +1,52 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ScanHIBP membaca dump SHA-1 format HIBP ("HASH:COUNT" per baris, COUNT opsional)
+// dan memanggil fn untuk setiap hash yang jumlah kemunculannya >= minCount
+func ScanHIBP(r io.Reader, minCount int, fn func(digest [sha1.Size]byte)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, countStr, hasCount := strings.Cut(line, ":")
+		if hasCount && minCount > 1 {
+			count, err := strconv.Atoi(strings.TrimSpace(countStr))
+			if err == nil && count < minCount {
+				continue
+			}
+		}
+
+		digest, err := parseDigest(hash)
+		if err != nil {
+			return err
+		}
+		fn(digest)
+	}
+
+	return scanner.Err()
+}
+
+func parseDigest(hash string) ([sha1.Size]byte, error) {
+	var digest [sha1.Size]byte
+	raw, err := hex.DecodeString(strings.TrimSpace(hash))
+	if err != nil {
+		return digest, err
+	}
+	if len(raw) != sha1.Size {
+		return digest, errors.New("panjang hash SHA-1 tidak valid: " + hash)
+	}
+	copy(digest[:], raw)
+	return digest, nil
+}
@@ -0,0 +1,50 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+)
+
+// Verifier memeriksa token captcha yang dikirim client
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+const (
+	recaptchaURL = "https://www.google.com/recaptcha/api/siteverify"
+	hcaptchaURL  = "https://api.hcaptcha.com/siteverify"
+	turnstileURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// NewVerifier mengembalikan nil jika captcha tidak diaktifkan
+func NewVerifier(c config.CaptchaConfig) (Verifier, error) {
+	switch c.Provider {
+	case "", config.CaptchaNone:
+		return nil, nil
+	case config.CaptchaAlwaysPass:
+		return NewStaticVerifier(true), nil
+	case config.CaptchaAlwaysFail:
+		return NewStaticVerifier(false), nil
+	case config.CaptchaRecaptcha:
+		return newSiteVerifier(c, recaptchaURL)
+	case config.CaptchaHcaptcha:
+		return newSiteVerifier(c, hcaptchaURL)
+	case config.CaptchaTurnstile:
+		return newSiteVerifier(c, turnstileURL)
+	}
+
+	return nil, fmt.Errorf("captcha provider %q tidak dikenal", c.Provider)
+}
+
+func newSiteVerifier(c config.CaptchaConfig, defaultURL string) (Verifier, error) {
+	if c.Secret == "" {
+		return nil, fmt.Errorf("CAPTCHA_SECRET wajib diisi untuk provider %s", c.Provider)
+	}
+
+	url := defaultURL
+	if c.VerifyURL != "" {
+		url = c.VerifyURL
+	}
+	return NewSiteVerifier(url, c.Secret, c.MinScore, c.Timeout), nil
+}
@@ -0,0 +1,74 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// siteVerifier memakai protokol siteverify yang sama untuk reCAPTCHA, hCaptcha dan Turnstile:
+// POST form secret, response, remoteip lalu membaca field success
+type siteVerifier struct {
+	url      string
+	secret   string
+	minScore float64
+	client   *http.Client
+}
+
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func NewSiteVerifier(verifyURL, secret string, minScore float64, timeout time.Duration) Verifier {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &siteVerifier{url: verifyURL, secret: secret, minScore: minScore, client: &http.Client{Timeout: timeout}}
+}
+
+func (v *siteVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, apperror.New(apperror.CodeInternalError, "gagal membuat request captcha", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDependencyError, "gagal menghubungi layanan captcha", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, apperror.New(apperror.CodeDependencyError, "layanan captcha bermasalah",
+			fmt.Errorf("status %d", resp.StatusCode))
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, apperror.New(apperror.CodeDecodingError, "respon captcha tidak valid", err)
+	}
+
+	// score hanya dikirim reCAPTCHA v3
+	if result.Success && result.Score != nil && *result.Score < v.minScore {
+		return false, nil
+	}
+
+	return result.Success, nil
+}
@@ -0,0 +1,16 @@
+package captcha
+
+import "context"
+
+// staticVerifier selalu lolos atau selalu gagal, untuk pengembangan lokal dan pengujian
+type staticVerifier struct {
+	pass bool
+}
+
+func NewStaticVerifier(pass bool) Verifier {
+	return &staticVerifier{pass: pass}
+}
+
+func (v *staticVerifier) Verify(_ context.Context, _, _ string) (bool, error) {
+	return v.pass, nil
+}
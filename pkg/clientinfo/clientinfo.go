@@ -0,0 +1,47 @@
+package clientinfo
+
+import (
+	"context"
+	"time"
+)
+
+// Info adalah detail request yang dibawa lewat context sampai ke service
+type Info struct {
+	IP        string
+	UserAgent string
+	Method    string
+	Path      string
+	Query     string
+	Headers   map[string]string
+	Time      time.Time
+}
+
+type ctxKey struct{}
+
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, ctxKey{}, info)
+}
+
+// FromContext mengembalikan Info kosong jika request tidak melewati ClientInfoMiddleware
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(ctxKey{}).(Info)
+	return info
+}
+
+// Fields meratakan Info menjadi pasangan key-value untuk log dan alert
+func (i Info) Fields() map[string]string {
+	fields := map[string]string{
+		"ip":         i.IP,
+		"user_agent": i.UserAgent,
+		"method":     i.Method,
+		"path":       i.Path,
+		"query":      i.Query,
+	}
+	if !i.Time.IsZero() {
+		fields["time"] = i.Time.Format(time.RFC3339)
+	}
+	for k, v := range i.Headers {
+		fields["header."+k] = v
+	}
+	return fields
+}
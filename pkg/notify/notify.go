@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Event adalah kejadian keamanan pada akun yang diberitahukan ke pemiliknya
+type Event string
+
+const (
+	EventNewLogin        Event = "new_login"
+	EventPasswordChanged Event = "password_changed"
+	EventEmailChanged    Event = "email_changed"
+	EventGoogleLinked    Event = "google_linked"
+	EventRoleChanged     Event = "role_changed"
+	EventMFADisabled     Event = "mfa_disabled"
+)
+
+var events = []Event{
+	EventNewLogin,
+	EventPasswordChanged,
+	EventEmailChanged,
+	EventGoogleLinked,
+	EventRoleChanged,
+	EventMFADisabled,
+}
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Data diisi oleh pemanggil, Details berisi keterangan khusus per event (ip, perangkat, dsb)
+type Data struct {
+	Name      string
+	Time      string
+	Details   map[string]string
+	RevokeURL string
+}
+
+type Renderer interface {
+	Render(event Event, data Data) (subject, body string, err error)
+}
+
+type renderer struct {
+	templates map[Event]*template.Template
+}
+
+// NewRenderer mem-parsing semua template saat start, template yang rusak langsung ketahuan
+func NewRenderer() (Renderer, error) {
+	templates := make(map[Event]*template.Template, len(events))
+	for _, e := range events {
+		t, err := template.ParseFS(templateFS, "templates/layout.html", fmt.Sprintf("templates/%s.html", e))
+		if err != nil {
+			return nil, fmt.Errorf("template notifikasi %s: %w", e, err)
+		}
+		templates[e] = t
+	}
+
+	return &renderer{templates: templates}, nil
+}
+
+func (r *renderer) Render(event Event, data Data) (string, string, error) {
+	t, ok := r.templates[event]
+	if !ok {
+		return "", "", fmt.Errorf("template notifikasi %s tidak ditemukan", event)
+	}
+
+	var subject, body bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := t.ExecuteTemplate(&body, "layout", data); err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
@@ -0,0 +1,33 @@
+package password
+
+import "strings"
+
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type PolicyError struct {
+	Violations []Violation
+}
+
+func NewPolicyError(rule, message string) *PolicyError {
+	return &PolicyError{Violations: []Violation{{Rule: rule, Message: message}}}
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+// FieldErrors mengubah pelanggaran ke format errors valigo, key-nya "<field>.<rule>"
+func (e *PolicyError) FieldErrors(field string) map[string]string {
+	errs := make(map[string]string, len(e.Violations))
+	for _, v := range e.Violations {
+		errs[field+"."+v.Rule] = v.Message
+	}
+	return errs
+}
@@ -0,0 +1,156 @@
+package password
+
+import (
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+	"github.com/gogaruda/auth/pkg/breach"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	RuleMinLength       = "min_length"
+	RuleMaxLength       = "max_length"
+	RuleUppercase       = "uppercase"
+	RuleLowercase       = "lowercase"
+	RuleDigit           = "digit"
+	RuleSymbol          = "symbol"
+	RuleBannedWord      = "banned_word"
+	RuleSimilarUsername = "similar_username"
+	RuleSimilarEmail    = "similar_email"
+	RuleBreached        = "breached"
+	RuleReused          = "reused"
+)
+
+type Policy interface {
+	Validate(in Input) error
+}
+
+// Input berisi password beserta data akun yang dipakai untuk cek kemiripan.
+// CheckBreach mengaktifkan pengecekan ke daftar password bocor.
+type Input struct {
+	Password    string
+	Username    string
+	Email       string
+	CheckBreach bool
+}
+
+type policy struct {
+	cfg    config.PasswordConfig
+	breach breach.Checker
+}
+
+func NewPolicy(c config.PasswordConfig, b breach.Checker) Policy {
+	return &policy{cfg: c, breach: b}
+}
+
+// Validate mengembalikan *PolicyError berisi semua aturan yang dilanggar, atau nil
+func (p *policy) Validate(in Input) error {
+	var violations []Violation
+	add := func(rule, message string) {
+		violations = append(violations, Violation{Rule: rule, Message: message})
+	}
+
+	length := utf8.RuneCountInString(in.Password)
+	if p.cfg.MinLength > 0 && length < p.cfg.MinLength {
+		add(RuleMinLength, fmt.Sprintf("password minimal %d karakter", p.cfg.MinLength))
+	}
+	if p.cfg.MaxLength > 0 && length > p.cfg.MaxLength {
+		add(RuleMaxLength, fmt.Sprintf("password maksimal %d karakter", p.cfg.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range in.Password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.cfg.RequireUpper && !hasUpper {
+		add(RuleUppercase, "password harus mengandung huruf besar")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		add(RuleLowercase, "password harus mengandung huruf kecil")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		add(RuleDigit, "password harus mengandung angka")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		add(RuleSymbol, "password harus mengandung simbol")
+	}
+
+	lower := strings.ToLower(in.Password)
+	for _, word := range p.cfg.BannedWords {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			add(RuleBannedWord, "password mengandung kata yang mudah ditebak")
+			break
+		}
+	}
+
+	if p.cfg.RejectSimilar {
+		if isSimilar(lower, in.Username) {
+			add(RuleSimilarUsername, "password terlalu mirip dengan username")
+		}
+
+		local, _, _ := strings.Cut(in.Email, "@")
+		if isSimilar(lower, local) || isSimilar(lower, in.Email) {
+			add(RuleSimilarEmail, "password terlalu mirip dengan email")
+		}
+	}
+
+	if in.CheckBreach && p.breach.IsBreached(in.Password) {
+		add(RuleBreached, "password ini pernah bocor di internet, gunakan password lain")
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyError{Violations: violations}
+}
+
+// isSimilar menganggap mirip jika salah satu memuat yang lain
+// atau jarak edit-nya kurang dari 30% panjang string terpanjang
+func isSimilar(password, value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if utf8.RuneCountInString(value) < 3 || password == "" {
+		return false
+	}
+
+	if strings.Contains(password, value) || strings.Contains(value, password) {
+		return true
+	}
+
+	a, b := []rune(password), []rune(value)
+	longest := max(len(a), len(b))
+	return levenshtein(a, b)*10 < longest*3
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter memakai algoritma sliding window counter: jumlah hit window sebelumnya
+// diberi bobot sesuai sisa porsinya di window berjalan, lalu ditambah hit window berjalan
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+type limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewLimiter(s Store) Limiter {
+	return &limiter{store: s, now: time.Now}
+}
+
+func (l *limiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	if rule.Limit <= 0 || rule.Window <= 0 {
+		return Result{Allowed: true, Limit: rule.Limit}, nil
+	}
+
+	now := l.now()
+	start := now.Truncate(rule.Window)
+	elapsed := now.Sub(start)
+
+	currentKey := fmt.Sprintf("%s:%d", key, start.UnixNano())
+	previousKey := fmt.Sprintf("%s:%d", key, start.Add(-rule.Window).UnixNano())
+
+	// hit dicatat dulu lalu dicek dari nilai hasil Incr, supaya request paralel tidak lolos bersama
+	// dari pembacaan yang sama. Request yang ditolak tetap terhitung.
+	// ttl dua window agar counter masih terbaca sebagai window sebelumnya
+	current, err := l.store.Incr(ctx, currentKey, 2*rule.Window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	previous, err := l.store.Get(ctx, previousKey)
+	if err != nil {
+		return Result{}, err
+	}
+
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimated := float64(previous)*weight + float64(current)
+	limit := float64(rule.Limit)
+
+	if estimated > limit {
+		return Result{
+			Allowed:    false,
+			Limit:      rule.Limit,
+			RetryAfter: retryAfter(previous, current, limit, elapsed, rule.Window),
+		}, nil
+	}
+
+	remaining := int(math.Floor(limit - estimated))
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{Allowed: true, Limit: rule.Limit, Remaining: remaining}, nil
+}
+
+// retryAfter menghitung kapan perkiraan jumlah hit turun cukup untuk satu request lagi
+func retryAfter(previous, current int64, limit float64, elapsed, window time.Duration) time.Duration {
+	untilNextWindow := window - elapsed
+	room := limit - 1 - float64(current)
+	if room < 0 || previous == 0 {
+		return untilNextWindow
+	}
+
+	// previous * (1 - t/window) <= room  =>  t >= window * (1 - room/previous)
+	target := time.Duration(float64(window) * (1 - room/float64(previous)))
+	if target <= elapsed {
+		return time.Second
+	}
+	return target - elapsed
+}
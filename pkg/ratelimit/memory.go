@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery adalah jumlah operasi Incr sebelum key kadaluarsa dibersihkan
+const sweepEvery = 1000
+
+type memoryEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// memoryStore menyimpan counter di memori proses, cocok untuk satu instance
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	ops     int
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *memoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.ops++
+	if s.ops >= sweepEvery {
+		s.sweep(now)
+		s.ops = 0
+	}
+
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		entry = memoryEntry{expiresAt: now.Add(ttl)}
+	}
+
+	entry.count++
+	s.entries[key] = entry
+	return entry.count, nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, nil
+	}
+	return entry.count, nil
+}
+
+func (s *memoryStore) sweep(now time.Time) {
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Store menyimpan counter per key. Implementasi lain (mis. redis) cukup memenuhi interface ini
+type Store interface {
+	// Incr menambah counter key sebesar 1 dan mengembalikan nilai terbaru, key kadaluarsa setelah ttl
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Get mengembalikan nilai counter key, 0 jika tidak ada atau sudah kadaluarsa
+	Get(ctx context.Context, key string) (int64, error)
+}
@@ -3,6 +3,7 @@ package response
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 type Responder interface {
@@ -13,6 +14,7 @@ type Responder interface {
 	Unauthorized(message string)
 	Forbidden(message string)
 	NotFound(message string)
+	TooManyRequests(message string, retryAfter int)
 	ServerError(message string)
 }
 
@@ -81,6 +83,15 @@ func (r *responder) NotFound(message string) {
 	})
 }
 
+func (r *responder) TooManyRequests(message string, retryAfter int) {
+	r.c.Header("Retry-After", strconv.Itoa(retryAfter))
+	r.c.AbortWithStatusJSON(http.StatusTooManyRequests, APIResponse{
+		Code:    http.StatusTooManyRequests,
+		Status:  "error",
+		Message: message,
+	})
+}
+
 func (r *responder) ServerError(message string) {
 	r.c.AbortWithStatusJSON(http.StatusInternalServerError, APIResponse{
 		Code:    http.StatusInternalServerError,
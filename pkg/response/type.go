@@ -13,4 +13,7 @@ type MetaData struct {
 	Page  int `json:"page,omitempty"`
 	Limit int `json:"limit,omitempty"`
 	Total int `json:"total,omitempty"`
+	// Next dan Prev cursor opaque untuk pagination keyset, kosong jika tidak ada halaman lagi
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
 }
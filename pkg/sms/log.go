@@ -0,0 +1,43 @@
+package sms
+
+import (
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// logSender tidak mengirim SMS sungguhan, hanya mencatat pesan ke file atau log
+type logSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogSender(path string) SmsSender {
+	return &logSender{path: path}
+}
+
+func (s *logSender) Send(to, message string) error {
+	if s.path == "" {
+		log.Printf("[SMS] to=%s message=%s", to, message)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal membuka file log sms", err)
+	}
+	defer f.Close()
+
+	line := fmt.Sprintf("%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, message)
+	if _, err := f.WriteString(line); err != nil {
+		return apperror.New(apperror.CodeInternalError, "gagal menulis log sms", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package sms
+
+import "github.com/gogaruda/auth/internal/config"
+
+type SmsSender interface {
+	Send(to, message string) error
+}
+
+func NewSmsSender(c config.SmsConfig) SmsSender {
+	switch c.Driver {
+	default:
+		return NewLogSender(c.LogPath)
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localStore menyimpan file di disk, cocok untuk development atau server tunggal
+type localStore struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocalStore(dir, publicURL string) BlobStore {
+	return &localStore{dir: dir, publicURL: strings.TrimSuffix(publicURL, "/")}
+}
+
+func (s *localStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("buat folder storage gagal: %w", err)
+	}
+
+	// tulis ke file sementara dulu agar pembaca tidak pernah melihat file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("buat file sementara gagal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("tulis file gagal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("tutup file gagal: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("ubah permission file gagal: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *localStore) Delete(_ context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("hapus file gagal: %w", err)
+	}
+	return nil
+}
+
+func (s *localStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *localStore) Key(url string) (string, bool) {
+	return keyFromURL(s.publicURL, url)
+}
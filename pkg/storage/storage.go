@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+	"path"
+	"strings"
+)
+
+// BlobStore menyimpan file berdasarkan key dengan format path, contoh "avatars/<user>/<id>_128.jpg"
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL mengembalikan alamat publik file
+	URL(key string) string
+	// Key kebalikan dari URL, false jika URL bukan milik store ini
+	Key(url string) (string, bool)
+}
+
+func NewBlobStore(c config.StorageConfig) (BlobStore, error) {
+	switch c.Driver {
+	case config.StorageLocal, "":
+		publicURL := c.PublicURL
+		if publicURL == "" {
+			publicURL = "/assets"
+		}
+		return NewLocalStore(c.LocalDir, publicURL), nil
+	case config.StorageS3:
+		return NewS3Store(c)
+	default:
+		return nil, fmt.Errorf("storage driver %q tidak dikenal", c.Driver)
+	}
+}
+
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("key storage %q tidak valid", key)
+	}
+	return nil
+}
+
+func keyFromURL(prefix, url string) (string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+
+	key := strings.TrimPrefix(url, prefix)
+	return key, validKey(key) == nil
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+)
+
+// DeviceFingerprint menggabungkan prefix IP (/24 untuk IPv4, /48 untuk IPv6) dan user agent,
+// sehingga pergantian IP dalam satu jaringan tidak dianggap perangkat baru
+func (u *utils) DeviceFingerprint(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(IPPrefix(ip) + "|" + strings.TrimSpace(userAgent)))
+	return hex.EncodeToString(sum[:])
+}
+
+func IPPrefix(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return ip
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
@@ -1,13 +1,188 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"strings"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+	// format hash ber-pepper: $pepper$v=<versi><hash argon2id/bcrypt>
+	pepperPrefix = "$pepper$v="
+)
+
+type argon2idHash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
 
 func (u *utils) GenerateHash(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	version := u.config.Hash.PepperVersion
+	hash, err := u.generateHash(u.pepper(password, version))
+	if err != nil || version == 0 {
+		return hash, err
+	}
+
+	return pepperPrefix + strconv.Itoa(version) + hash, nil
+}
+
+func (u *utils) generateHash(password string) (string, error) {
+	cfg := u.config.Hash
+	if cfg.Algorithm == config.HashBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
+		return string(bytes), err
+	}
+
+	salt := make([]byte, cfg.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, cfg.Argon2Iterations, cfg.Argon2Memory, cfg.Argon2Parallelism, cfg.Argon2KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
+// CompareHash mengenali versi pepper dan format hash (argon2id atau bcrypt) dari prefix-nya
 func (u *utils) CompareHash(hash, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	version, hash, ok := splitPepper(hash)
+	if !ok {
+		return false
+	}
+	if _, exists := u.config.Hash.Peppers[version]; version != 0 && !exists {
+		return false
+	}
+	password = u.pepper(password, version)
+
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil
+	}
+
+	h, err := decodeArgon2id(hash)
+	if err != nil {
+		return false
+	}
+
+	key := argon2.IDKey([]byte(password), h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1
+}
+
+// NeedsRehash bernilai true jika hash dibuat dengan pepper, algoritma atau parameter yang bukan konfigurasi saat ini
+func (u *utils) NeedsRehash(hash string) bool {
+	cfg := u.config.Hash
+	version, hash, ok := splitPepper(hash)
+	if !ok || version != cfg.PepperVersion {
+		return true
+	}
+
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		if cfg.Algorithm != config.HashBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != cfg.BcryptCost
+	}
+
+	if cfg.Algorithm != config.HashArgon2id {
+		return true
+	}
+
+	h, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+
+	return h.memory != cfg.Argon2Memory ||
+		h.iterations != cfg.Argon2Iterations ||
+		h.parallelism != cfg.Argon2Parallelism ||
+		uint32(len(h.salt)) != cfg.Argon2SaltLength ||
+		uint32(len(h.key)) != cfg.Argon2KeyLength
+}
+
+// pepper mencampur password dengan HMAC-SHA256 dari pepper versi tersebut.
+// Hasilnya 43 karakter sehingga tidak terpotong batas 72 byte bcrypt.
+func (u *utils) pepper(password string, version int) string {
+	if version == 0 {
+		return password
+	}
+
+	mac := hmac.New(sha256.New, u.config.Hash.Peppers[version])
+	mac.Write([]byte(password))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// splitPepper memisahkan versi pepper dari hash, hash tanpa pepper bernilai versi 0
+func splitPepper(hash string) (int, string, bool) {
+	if !strings.HasPrefix(hash, pepperPrefix) {
+		return 0, hash, true
+	}
+
+	rest := strings.TrimPrefix(hash, pepperPrefix)
+	idx := strings.Index(rest, "$")
+	if idx <= 0 {
+		return 0, "", false
+	}
+
+	version, err := strconv.Atoi(rest[:idx])
+	if err != nil || version <= 0 {
+		return 0, "", false
+	}
+	return version, rest[idx:], true
+}
+
+func decodeArgon2id(hash string) (*argon2idHash, error) {
+	// format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, errors.New("format hash argon2id tidak valid")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, errors.New("versi argon2 tidak didukung")
+	}
+
+	var h argon2idHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.iterations, &h.parallelism); err != nil {
+		return nil, err
+	}
+	// t atau p bernilai 0 membuat argon2 panic, m yang terlalu besar menghabiskan memori
+	if h.iterations < 1 || h.iterations > config.Argon2MaxIterations ||
+		h.parallelism < 1 || h.parallelism > config.Argon2MaxParallelism ||
+		h.memory < 8*uint32(h.parallelism) || h.memory > config.Argon2MaxMemory {
+		return nil, errors.New("parameter hash argon2id di luar batas")
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	// key kosong membuat IDKey menghasilkan key kosong juga sehingga password apa pun cocok
+	if len(h.salt) < config.Argon2MinSaltLength || len(h.salt) > config.Argon2MaxSaltLength ||
+		len(h.key) < config.Argon2MinKeyLength || len(h.key) > config.Argon2MaxKeyLength {
+		return nil, errors.New("panjang salt atau key argon2id di luar batas")
+	}
+
+	return &h, nil
 }
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/gogaruda/auth/internal/config"
+	"golang.org/x/crypto/argon2"
+	"testing"
+)
+
+func newTestUtils() Utils {
+	return NewUtils(&config.AppConfig{Hash: config.HashConfig{
+		Algorithm:         config.HashArgon2id,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+	}})
+}
+
+func argon2Hash(salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=64,t=1,p=1$%s$%s", argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func TestCompareHashRoundTrip(t *testing.T) {
+	ut := newTestUtils()
+	hash, err := ut.GenerateHash("rahasia-benar")
+	if err != nil {
+		t.Fatalf("GenerateHash: %v", err)
+	}
+
+	if !ut.CompareHash(hash, "rahasia-benar") {
+		t.Error("password benar harus cocok")
+	}
+	if ut.CompareHash(hash, "rahasia-salah") {
+		t.Error("password salah tidak boleh cocok")
+	}
+}
+
+func TestCompareHashRejectsEmptyKey(t *testing.T) {
+	ut := newTestUtils()
+	salt := []byte("0123456789abcdef")
+
+	for name, hash := range map[string]string{
+		"key kosong":  argon2Hash(salt, nil),
+		"key pendek":  argon2Hash(salt, argon2.IDKey([]byte("apa saja"), salt, 1, 64, 1, 4)),
+		"salt kosong": argon2Hash(nil, argon2.IDKey([]byte("apa saja"), nil, 1, 64, 1, 32)),
+	} {
+		for _, password := range []string{"", "apa saja", "password lain"} {
+			if ut.CompareHash(hash, password) {
+				t.Errorf("%s: password %q tidak boleh cocok", name, password)
+			}
+		}
+	}
+}
+
+func TestDecodeArgon2idBounds(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+
+	for _, params := range []string{"m=65536,t=0,p=2", "m=65536,t=3,p=0", "m=4294967295,t=3,p=2", "m=65536,t=3,p=300"} {
+		hash := fmt.Sprintf("$argon2id$v=%d$%s$%s$%s", argon2.Version, params, salt, key)
+		if _, err := decodeArgon2id(hash); err == nil {
+			t.Errorf("parameter %s harus ditolak", params)
+		}
+	}
+}
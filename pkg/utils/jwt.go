@@ -22,3 +22,36 @@ func (u *utils) GenerateJWT(userID, tokenVersion string, isVerified bool, roles
 
 	return token.SignedString([]byte(secret))
 }
+
+// GenerateRestrictedJWT membuat token tanpa role yang hanya berlaku untuk scope tertentu
+func (u *utils) GenerateRestrictedJWT(userID, tokenVersion, scope string, cfg *config.AppConfig) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id":       userID,
+		"token_version": tokenVersion,
+		"is_verified":   false,
+		"roles":         []string{},
+		"scope":         scope,
+		"exp":           now.Add(cfg.JWT.AccessTokenTTL).Unix(),
+		"iat":           now.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWT.Secret))
+}
+
+// GenerateCanaryJWT membuat token umpan yang terlihat seperti token biasa namun berumur panjang
+func (u *utils) GenerateCanaryJWT(userID string, roles []string, ttl time.Duration, cfg *config.AppConfig) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id":       userID,
+		"token_version": u.GenerateULID(),
+		"is_verified":   true,
+		"roles":         roles,
+		"exp":           now.Add(ttl).Unix(),
+		"iat":           now.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWT.Secret))
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizePhone mengubah nomor telepon ke format E.164 (+6281234567890).
+// Nomor berawalan 0 dianggap nomor lokal dan diberi kode negara default.
+func (u *utils) NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", errors.New("nomor telepon mengandung karakter tidak valid")
+		}
+	}
+
+	num := digits.String()
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(phone), "+"):
+	case strings.HasPrefix(num, "00"):
+		num = num[2:]
+	case strings.HasPrefix(num, "0"):
+		num = u.config.Sms.DefaultCountryCode + num[1:]
+	}
+
+	if len(num) < 8 || len(num) > 15 || num[0] == '0' {
+		return "", errors.New("nomor telepon tidak valid")
+	}
+
+	return "+" + num, nil
+}
@@ -1,12 +1,25 @@
 package utils
 
-import "github.com/gogaruda/auth/internal/config"
+import (
+	"github.com/gogaruda/auth/internal/config"
+	"time"
+)
+
+// ScopePasswordChange membatasi token hanya untuk endpoint ganti password
+const ScopePasswordChange = "password_change"
 
 type Utils interface {
 	GenerateJWT(userID, tokenVersion string, isVerified bool, roles []string, cfg *config.AppConfig) (string, error)
+	GenerateRestrictedJWT(userID, tokenVersion, scope string, cfg *config.AppConfig) (string, error)
+	GenerateCanaryJWT(userID string, roles []string, ttl time.Duration, cfg *config.AppConfig) (string, error)
 	GenerateULID() string
 	GenerateHash(password string) (string, error)
 	CompareHash(hash, password string) bool
+	NeedsRehash(hash string) bool
+	GenerateOTP(length int) (string, error)
+	HashToken(token string) string
+	NormalizePhone(phone string) (string, error)
+	DeviceFingerprint(ip, userAgent string) string
 }
 
 type utils struct {